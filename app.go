@@ -15,11 +15,13 @@
 package main
 
 import (
-	"time"
+	"fmt"
+	"os"
 
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/cli"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	pSentry "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/sentry"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
 	"github.com/getsentry/sentry-go"
 	"go.uber.org/zap"
@@ -27,21 +29,30 @@ import (
 
 func main() {
 	// Collecting config from env or file or flag
-	cfg := config.GetConfig()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	logger, err := logger.NewRootLogger(cfg.Debug, cfg.IsDevelopment)
+	logger, err := logger.NewRootLogger(cfg.LogLevel, cfg.Debug, cfg.IsDevelopment)
 	if err != nil {
 		panic(err)
 	}
 	zap.ReplaceGlobals(logger)
 
+	if err := pSentry.Init(cfg); err != nil {
+		panic(err)
+	}
+	defer sentry.Flush(cfg.SentryFlushTimeout)
+
 	// this function will logged error log in sentry
 	sentryLoggedFunc := func() {
 		err := recover()
 
 		if err != nil {
 			sentry.CurrentHub().Recover(err)
-			sentry.Flush(time.Second * 2)
+			sentry.Flush(cfg.SentryFlushTimeout)
 		}
 	}
 
@@ -49,9 +60,11 @@ func main() {
 	routinewrapper.Init(sentryLoggedFunc)
 	defer sentryLoggedFunc()
 
-	err = cli.Init(cfg, logger)
-	if err != nil {
-		panic(err)
+	// The command already printed the error, failing commands exit non-zero
+	// for scripts. Panics are still left to the sentry recover above.
+	if err := cli.Init(cfg, logger); err != nil {
+		sentry.Flush(cfg.SentryFlushTimeout)
+		os.Exit(1)
 	}
 
 }
@@ -1,16 +1,26 @@
 package cli
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
 	_ "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/docs"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/middlewares"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/health"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/listener"
 	pMetrics "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/prometheus"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routes"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 
@@ -23,40 +33,146 @@ func GetAPICommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
 		Use:   "api",
 		Short: "To start api",
 		Long:  `To start api`,
+		// Config and listener errors are not usage mistakes
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only serving needs a port and the datasets configured
+			if errs := config.ValidateServer(cfg); len(errs) > 0 {
+				return errs
+			}
+
+			// Tracing has to be installed before any span is started
+			shutdownTracing, err := tracing.Init(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
 
 			// Create fiber app
-			app := fiber.New(fiber.Config{})
+			app := fiber.New(fiber.Config{
+				ErrorHandler: middlewares.ErrorHandler(logger, cfg),
+			})
 			app.Get("/swagger/*", swagger.HandlerDefault) // Serve Swagger UI
 
 			promMetrics := pMetrics.InitPrometheusMetrics()
 
 			// Setup routes
-			err := routes.Setup(app, logger, cfg, promMetrics)
+			err = routes.Setup(app, logger, cfg, promMetrics)
 			if err != nil {
 				return err
 			}
 
+			// Rows that do not decode are quarantined, the service starts
+			// with the valid ones
+			loadDatasets(cmd.Context(), cfg, logger)
+
 			interrupt := make(chan os.Signal, 1)
 			signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-			// Start server in a goroutine
+			// SIGHUP reloads the config
+			hangup := make(chan os.Signal, 1)
+			signal.Notify(hangup, syscall.SIGHUP)
+			defer signal.Stop(hangup)
 			go func() {
-				if err := app.Listen(cfg.Host + ":" + cfg.Port); err != nil {
-					logger.Panic(err.Error())
+				for range hangup {
+					reloadConfig(cfg, logger)
 				}
 			}()
 
-			<-interrupt
-			logger.Info("gracefully shutting down...")
-			if err := app.Shutdown(); err != nil {
-				logger.Panic("error while shutting down server", zap.Error(err))
+			ln, err := listener.New(cfg, logger)
+			if err != nil {
+				return err
 			}
 
+			// Start server in a goroutine
+			listenErr := make(chan error, 1)
+			go func() {
+				listenErr <- app.Listener(ln)
+			}()
+
+			select {
+			case err := <-listenErr:
+				// The server never started or stopped by itself
+				logger.Error("error while serving", zap.Error(err))
+				return err
+			case <-interrupt:
+			}
+
+			// Fail readiness first so load balancers stop sending traffic
+			// before the listener closes
+			logger.Info("gracefully shutting down...", zap.Duration("drain_delay", cfg.ShutdownDrainDelay))
+			health.SetDraining()
+			time.Sleep(cfg.ShutdownDrainDelay)
+
+			if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+				logger.Error("error while shutting down server", zap.Error(err))
+			}
 			logger.Info("server stopped receiving new requests.")
+
+			// Let background jobs such as webhook deliveries finish
+			jobsCtx, cancelJobs := context.WithTimeout(context.Background(), cfg.JobsShutdownTimeout)
+			defer cancelJobs()
+			if err := routinewrapper.Shutdown(jobsCtx); err != nil {
+				logger.Error("error while stopping background jobs", zap.Error(err))
+			}
+
+			// Wait for writes still in flight and sync the data files
+			storageCtx, cancelStorage := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			defer cancelStorage()
+			if err := models.Shutdown(storageCtx, cfg); err != nil {
+				logger.Error("error while flushing storage", zap.Error(err))
+			}
+
+			if err := shutdownTracing(context.Background()); err != nil {
+				logger.Error("error while flushing traces", zap.Error(err))
+			}
 			return nil
 		},
 	}
 
+	apiCommand.Flags().AddFlagSet(config.Flags())
+
 	return apiCommand
 }
+
+// reloadConfig applies the reloadable settings of the config as it is now
+// on disk and in the environment, keeping the current config on errors,
+// and reads the datasets again
+func reloadConfig(cfg config.AppConfig, logger *zap.Logger) {
+	ctx := utils.ContextWithActor(context.Background(), constants.SignalActor)
+	changes, restart, err := config.Reload(ctx)
+	if err != nil {
+		logger.Error("error reloading config, keeping the current one", zap.Error(err))
+		return
+	}
+
+	changed := make([]string, 0, len(changes))
+	for _, change := range changes {
+		changed = append(changed, change.Key)
+	}
+	logger.Info("reloaded config", zap.Strings("changed", changed), zap.Strings("restart_required", restart))
+
+	rows, err := models.ReloadDatasets(ctx, logger, cfg)
+	if err != nil {
+		logger.Error("error reloading datasets", zap.Error(err))
+		return
+	}
+	logger.Info("reloaded datasets", zap.Int("apps", rows[models.EntityApp]), zap.Int("reviews", rows[models.EntityReview]))
+}
+
+// loadDatasets fills the app and review caches before serving. A dataset
+// that cannot be read is logged and loaded again by the first request.
+func loadDatasets(ctx context.Context, cfg config.AppConfig, logger *zap.Logger) {
+	apps, err := models.NewAppModel(logger, cfg).GetAppsFromCache(ctx)
+	if err != nil {
+		logger.Error(constants.ErrorLoadingCache, zap.Error(err))
+	} else {
+		logger.Info("loaded apps", zap.Int("rows", len(apps)))
+	}
+
+	reviews, err := models.NewReviewModel(logger, cfg).ListReviewsFromCache(ctx)
+	if err != nil {
+		logger.Error(constants.ErrParsingReviewsCSV, zap.Error(err))
+	} else {
+		logger.Info("loaded reviews", zap.Int("rows", len(reviews)))
+	}
+}
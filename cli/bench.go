@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"time"
+
+	"github.com/jszwec/csvutil"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/csvdialect"
+
+	"github.com/spf13/cobra"
+)
+
+// heapMetric is the runtime metric sampled for the peak heap of a run
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// benchResult is the time and memory one way of parsing a file took
+type benchResult struct {
+	name      string
+	rows      int
+	elapsed   time.Duration
+	allocated uint64
+	peakHeap  uint64
+}
+
+// GetBenchCommandDef measures parsing a generated apps CSV file with and without streaming
+func GetBenchCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	// Standard output is kept for the results
+	logger = log.ToStderr(logger)
+
+	var rows int
+	var path string
+	var keep bool
+	var workers []int
+
+	benchCommand := cobra.Command{
+		Use:   "bench",
+		Short: "To benchmark parsing a large apps CSV file",
+		Long: `To generate an apps CSV file of the given size and parse it: first by loading
+the whole file and decoding it into a slice, as the server used to, then by streaming
+it with each of the given numbers of workers. Reports time, bytes allocated and the peak
+live heap of each run. The file is written in the apps dialect.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if rows <= 0 {
+				return fmt.Errorf("--rows must be positive")
+			}
+			dialect, err := cfg.AppsDialect()
+			if err != nil {
+				return err
+			}
+
+			if path == "" {
+				dir, err := os.MkdirTemp("", "bench")
+				if err != nil {
+					return err
+				}
+				path = filepath.Join(dir, "apps.csv")
+				if !keep {
+					defer os.RemoveAll(dir)
+				}
+			}
+			start := time.Now()
+			size, err := generateApps(path, dialect, rows)
+			if err != nil {
+				return err
+			}
+			logger.Info("generated apps file", zap.String("file", path), zap.Int("rows", rows),
+				zap.Int64("bytes", size), zap.Duration("took", time.Since(start)))
+
+			var results []benchResult
+			result, err := measure("read all", func() (int, error) {
+				return readAllApps(path, dialect)
+			})
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+
+			for _, n := range workers {
+				name := fmt.Sprintf("stream, %d workers", n)
+				if n <= 0 {
+					name = fmt.Sprintf("stream, %d workers", runtime.GOMAXPROCS(0))
+				}
+				result, err := measure(name, func() (int, error) {
+					return streamApps(path, cfg, n)
+				})
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+
+			printBench(cmd.OutOrStdout(), size, results)
+			return nil
+		},
+	}
+	benchCommand.Flags().IntVar(&rows, "rows", 2_000_000, "rows to generate")
+	benchCommand.Flags().StringVar(&path, "file", "", "file to generate, a temporary file by default")
+	benchCommand.Flags().BoolVar(&keep, "keep", false, "keep the generated temporary file")
+	benchCommand.Flags().IntSliceVar(&workers, "workers", []int{1, 0}, "numbers of workers to stream with, 0 for one per CPU")
+
+	return benchCommand
+}
+
+// generateApps writes rows apps to path in dialect and returns its size
+func generateApps(path string, dialect csvdialect.Dialect, rows int) (int64, error) {
+	header, err := csvutil.Header(models.App{}, "csv")
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	writer := dialect.NewWriter(buffered)
+	if err := writer.WriteHeader(header); err != nil {
+		return 0, err
+	}
+	for i := 0; i < rows; i++ {
+		err := writer.Write([]string{
+			"Generated App " + strconv.Itoa(i),
+			"TOOLS",
+			strconv.FormatFloat(float64(i%50)/10, 'f', 1, 64),
+			strconv.Itoa(i % 100000),
+			strconv.Itoa(1+i%100) + "M",
+			"1,000,000+",
+			"Paid",
+			"$" + strconv.Itoa(i%10) + ".99",
+			"Everyone",
+			"Tools",
+			"January 7, 2018",
+			"1." + strconv.Itoa(i%10),
+			"4.0.3 and up",
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// readAllApps parses path the way the server did before streaming: the
+// whole file is read, then decoded into a slice of apps at once
+func readAllApps(path string, dialect csvdialect.Dialect) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	decoder, err := csvutil.NewDecoder(dialect.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return 0, err
+	}
+	var apps []models.App
+	if err := decoder.Decode(&apps); err != nil {
+		return 0, err
+	}
+	return len(apps), nil
+}
+
+// streamApps parses path with the streaming parser, keeping no rows
+func streamApps(path string, cfg config.AppConfig, workers int) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	_, err = models.StreamApps(file, cfg, workers, func(models.App) { count++ })
+	return count, err
+}
+
+// measure runs parse once and reports its time, the bytes it allocated
+// and the peak live heap sampled while it ran
+func measure(name string, parse func() (int, error)) (benchResult, error) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	samples := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(samples)
+	base := samples[0].Value.Uint64()
+	peak := base
+
+	stop := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				metrics.Read(samples)
+				peak = max(peak, samples[0].Value.Uint64())
+			}
+		}
+	}()
+
+	start := time.Now()
+	rows, err := parse()
+	elapsed := time.Since(start)
+	close(stop)
+	<-sampled
+	if err != nil {
+		return benchResult{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	runtime.ReadMemStats(&after)
+	return benchResult{
+		name:      name,
+		rows:      rows,
+		elapsed:   elapsed,
+		allocated: after.TotalAlloc - before.TotalAlloc,
+		peakHeap:  peak - base,
+	}, nil
+}
+
+// printBench writes one line per result
+func printBench(w io.Writer, size int64, results []benchResult) {
+	fmt.Fprintf(w, "file: %.1f MiB\n", float64(size)/(1<<20))
+	fmt.Fprintf(w, "%-22s %10s %10s %12s %14s %14s\n", "parser", "rows", "time", "rows/s", "allocated", "peak heap")
+	for _, r := range results {
+		fmt.Fprintf(w, "%-22s %10d %10s %12.0f %11.1f MiB %11.1f MiB\n",
+			r.name, r.rows, r.elapsed.Round(time.Millisecond), float64(r.rows)/r.elapsed.Seconds(),
+			float64(r.allocated)/(1<<20), float64(r.peakHeap)/(1<<20))
+	}
+}
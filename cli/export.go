@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// GetExportCommandDef writes apps or reviews to a file using the list filters of the API
+func GetExportCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	// Standard output is kept for the data
+	logger = log.ToStderr(logger)
+
+	var entity, format, output, priceFilter, asOfValue, appName, sentiment string
+	var limit, page int
+	var polarityMin, polarityMax float64
+
+	exportCommand := cobra.Command{
+		Use:   "export",
+		Short: "To export apps or reviews as CSV, JSON or NDJSON",
+		Long: `To write the apps or reviews matching the same filters as the list endpoints
+to standard output, or to a file replaced atomically with --output.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var asOf time.Time
+			if asOfValue != "" {
+				var err error
+				if asOf, err = time.Parse(time.RFC3339, asOfValue); err != nil {
+					return fmt.Errorf("%s: %w", constants.ErrInvalidTimeFilter, err)
+				}
+			}
+			if limit < 0 {
+				return fmt.Errorf("%s: %d", constants.ErrorInvalidLimit, limit)
+			}
+			if page < 1 {
+				return fmt.Errorf("%s: %d", constants.ErrorInvalidOffset, page)
+			}
+
+			// A file is only replaced once the whole export succeeded
+			var w io.Writer = cmd.OutOrStdout()
+			var buf bytes.Buffer
+			if output != "" {
+				w = &buf
+			}
+
+			var count int
+			var err error
+			switch entity {
+			case models.EntityApp:
+				count, err = models.NewAppModel(logger, cfg).Export(cmd.Context(), w, format, limit, page, priceFilter, asOf)
+			case models.EntityReview:
+				count, err = models.NewReviewModel(logger, cfg).Export(cmd.Context(), w, format, appName, sentiment, polarityMin, polarityMax, asOf)
+			default:
+				return fmt.Errorf("%s: %q", constants.ErrInvalidEntity, entity)
+			}
+			if err != nil {
+				return err
+			}
+			if output != "" {
+				if err := utils.WriteFileAtomic(output, buf.Bytes(), 0o644); err != nil {
+					return err
+				}
+			}
+
+			logger.Info("exported rows", zap.String("entity", entity), zap.String("format", format), zap.Int("rows", count))
+			return nil
+		},
+	}
+	exportCommand.Flags().StringVar(&entity, "entity", models.EntityApp, "what to export: app or review")
+	exportCommand.Flags().StringVar(&format, "format", models.FormatCSV, "csv, json or ndjson")
+	exportCommand.Flags().StringVarP(&output, "output", "o", "", "file to write instead of standard output")
+	exportCommand.Flags().StringVar(&asOfValue, constants.ParamAsOf, "", "export the rows as they were at this RFC 3339 time")
+	exportCommand.Flags().IntVar(&limit, constants.Limit, 0, "apps per page, 0 exports every app")
+	exportCommand.Flags().IntVar(&page, constants.Offset, 1, "page of apps to export with --limit")
+	exportCommand.Flags().StringVar(&priceFilter, constants.ParamFilterPrice, "", "only apps with this price, such as free")
+	exportCommand.Flags().StringVar(&appName, constants.ParamAppName, "", "only reviews of this app")
+	exportCommand.Flags().StringVar(&sentiment, constants.ParamSentiment, "", "only reviews with this sentiment")
+	exportCommand.Flags().Float64Var(&polarityMin, constants.ParamPolarityMin, -1, "minimum review polarity")
+	exportCommand.Flags().Float64Var(&polarityMax, constants.ParamPolarityMax, 1, "maximum review polarity")
+
+	return exportCommand
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routes"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// fileReport is the import report of one file
+type fileReport struct {
+	File string `json:"file"`
+	models.ImportReport
+}
+
+// GetImportCommandDef loads apps or reviews from files into the configured storage
+func GetImportCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	// Standard output is kept for the data
+	logger = log.ToStderr(logger)
+
+	var entity, format, actor string
+	var dryRun, strict bool
+
+	importCommand := cobra.Command{
+		Use:   "import FILE...",
+		Short: "To import apps or reviews from CSV, JSON or NDJSON files",
+		Long: `To validate rows from CSV, JSON or NDJSON files and add them to the configured storage.
+Invalid rows and rows refused by the duplicate policy are skipped and listed in the
+report printed on stdout. Use - to read standard input, which needs --format.`,
+		Args: cobra.MinimumNArgs(1),
+		// Row errors are reported in the output, not as usage mistakes
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if entity != models.EntityApp && entity != models.EntityReview {
+				return fmt.Errorf("%s: %q", constants.ErrInvalidEntity, entity)
+			}
+			if _, err := routes.SetupAudit(logger, cfg); err != nil {
+				return err
+			}
+			ctx := utils.ContextWithActor(cmd.Context(), actor)
+
+			reports, err := importFiles(ctx, cfg, logger, args, cmd.InOrStdin(), entity, format, dryRun, strict)
+			if reports != nil {
+				printJSON(cmd.OutOrStdout(), reports)
+			}
+			return err
+		},
+	}
+	importCommand.Flags().StringVar(&entity, "entity", models.EntityApp, "what the files hold: app or review")
+	importCommand.Flags().StringVar(&format, "format", "", "csv, json or ndjson, by default taken from the file extension")
+	importCommand.Flags().StringVar(&actor, "actor", constants.CLIActor, "actor recorded in the audit log")
+	importCommand.Flags().BoolVar(&dryRun, "dry-run", false, "validate and report without writing")
+	importCommand.Flags().BoolVar(&strict, "strict", false, "write nothing unless every row can be imported")
+
+	return importCommand
+}
+
+// importFiles imports paths in one go: every file is read before any row
+// is written, and the duplicate policy sees the rows of earlier files
+func importFiles(ctx context.Context, cfg config.AppConfig, logger *zap.Logger, paths []string, stdin io.Reader, entity, format string, dryRun, strict bool) ([]fileReport, error) {
+	sources := make([]models.ImportSource, 0, len(paths))
+	for _, path := range paths {
+		source, err := openSource(path, stdin, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if file, ok := source.Reader.(*os.File); ok && path != "-" {
+			defer file.Close()
+		}
+		sources = append(sources, source)
+	}
+
+	var reports []models.ImportReport
+	var err error
+	if entity == models.EntityReview {
+		reports, err = models.NewReviewModel(logger, cfg).ImportAll(ctx, sources, dryRun, strict)
+	} else {
+		reports, err = models.NewAppModel(logger, cfg).ImportAll(ctx, sources, dryRun, strict)
+	}
+	if reports == nil {
+		return nil, err
+	}
+
+	fileReports := make([]fileReport, 0, len(reports))
+	for i, report := range reports {
+		logger.Info("imported file",
+			zap.String("file", paths[i]),
+			zap.Int("imported", report.Imported),
+			zap.Int("merged", report.Merged),
+			zap.Int("invalid", report.Invalid),
+			zap.Int("rejected", report.Rejected),
+			zap.Bool("dry_run", dryRun),
+		)
+		fileReports = append(fileReports, fileReport{File: paths[i], ImportReport: report})
+	}
+	return fileReports, err
+}
+
+// openSource opens a single file, or standard input for "-"
+func openSource(path string, stdin io.Reader, format string) (models.ImportSource, error) {
+	var err error
+	if format == "" {
+		if format, err = models.FormatFromPath(path); err != nil {
+			return models.ImportSource{}, err
+		}
+	}
+	if path == "-" {
+		return models.ImportSource{Name: path, Reader: stdin, Format: format}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return models.ImportSource{}, err
+	}
+	return models.ImportSource{Name: path, Reader: file, Format: format}, nil
+}
+
+// printJSON writes v as indented JSON
+func printJSON(w io.Writer, v interface{}) {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
@@ -9,8 +9,21 @@ import (
 // Init app initialization
 func Init(cfg config.AppConfig, logger *zap.Logger) error {
 	apiCmd := GetAPICommandDef(cfg, logger)
+	purgeCmd := GetPurgeCommandDef(cfg, logger)
+	importCmd := GetImportCommandDef(cfg, logger)
+	exportCmd := GetExportCommandDef(cfg, logger)
+	validateCmd := GetValidateCommandDef(cfg, logger)
+	benchCmd := GetBenchCommandDef(cfg, logger)
 
 	rootCmd := &cobra.Command{Use: "golang-api"}
+	// Config flags are applied by config.Load before the commands run,
+	// they are registered here so cobra accepts and documents them
+	rootCmd.PersistentFlags().AddFlagSet(config.FileFlags())
 	rootCmd.AddCommand(&apiCmd)
+	rootCmd.AddCommand(&purgeCmd)
+	rootCmd.AddCommand(&importCmd)
+	rootCmd.AddCommand(&exportCmd)
+	rootCmd.AddCommand(&validateCmd)
+	rootCmd.AddCommand(&benchCmd)
 	return rootCmd.Execute()
 }
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routes"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// GetPurgeCommandDef permanently removes old trash entries
+func GetPurgeCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	var olderThan = cfg.TrashRetention
+	var actor string
+
+	purgeCommand := cobra.Command{
+		Use:   "purge",
+		Short: "To purge deleted rows from the trash",
+		Long: `To permanently remove trash entries deleted longer ago than the retention.
+The purge is recorded in the audit log and the revision history.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := routes.SetupAudit(logger, cfg); err != nil {
+				return err
+			}
+			ctx := utils.ContextWithActor(cmd.Context(), actor)
+
+			purged, err := models.NewTrashModel(logger, cfg).Purge(ctx, olderThan)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("purged trash", zap.Int("purged", purged), zap.Duration("older_than", olderThan))
+			return nil
+		},
+	}
+	purgeCommand.Flags().DurationVar(&olderThan, "older-than", cfg.TrashRetention, "remove entries deleted longer ago than this")
+	purgeCommand.Flags().StringVar(&actor, "actor", constants.CLIActor, "actor recorded in the audit log")
+
+	return purgeCommand
+}
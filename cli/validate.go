@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the validate report
+const (
+	reportText = "text"
+	reportJSON = "json"
+)
+
+// GetValidateCommandDef checks the apps and reviews datasets and reports every broken row
+func GetValidateCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	// Standard output is kept for the report
+	logger = log.ToStderr(logger)
+
+	var appsPath, reviewsPath, format string
+	var strict bool
+
+	validateCommand := cobra.Command{
+		Use:   "validate",
+		Short: "To check the apps and reviews datasets",
+		Long: `To parse both CSV datasets and report, per row and rule, headers that do not
+match the struct tags, shifted columns, values of the wrong type, failed validation
+rules, duplicate apps and reviews of unknown apps. Exits non-zero on errors.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != reportText && format != reportJSON {
+				return fmt.Errorf("unsupported report format %q, expected text or json", format)
+			}
+
+			report, err := models.ValidateDatasets(cfg, appsPath, reviewsPath)
+			if err != nil {
+				return err
+			}
+			if format == reportJSON {
+				printJSON(cmd.OutOrStdout(), report)
+			} else {
+				printReport(cmd.OutOrStdout(), report)
+			}
+
+			logger.Debug("validated datasets", zap.Int("errors", report.Errors), zap.Int("warnings", report.Warnings))
+			if report.Errors > 0 || (strict && report.Warnings > 0) {
+				return fmt.Errorf("datasets have %d errors and %d warnings", report.Errors, report.Warnings)
+			}
+			return nil
+		},
+	}
+	validateCommand.Flags().StringVar(&appsPath, "apps", cfg.CSVFilePath, "apps CSV file")
+	validateCommand.Flags().StringVar(&reviewsPath, "reviews", cfg.ReviewFilePath, "reviews CSV file")
+	validateCommand.Flags().StringVar(&format, "format", reportText, "report format: text or json")
+	validateCommand.Flags().BoolVar(&strict, "strict", false, "exit non-zero on warnings too")
+
+	return validateCommand
+}
+
+// printReport writes report as one line per issue followed by a summary
+func printReport(w io.Writer, report models.QualityReport) {
+	for _, issue := range report.Issues {
+		location := fmt.Sprintf("%s:%d", issue.File, issue.Line)
+		if issue.Column != "" {
+			location += fmt.Sprintf(" [%s]", issue.Column)
+		}
+		fmt.Fprintf(w, "%s: %s %s: %s\n", location, issue.Severity, issue.Rule, issue.Message)
+	}
+	if len(report.Issues) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	for _, file := range report.Files {
+		fmt.Fprintf(w, "%s: %d rows, %d valid\n", file.File, file.Rows, file.Valid)
+	}
+
+	rules := make([]string, 0, len(report.Rules))
+	for rule := range report.Rules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		fmt.Fprintf(w, "  %-18s %d\n", rule, report.Rules[rule])
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings\n", report.Errors, report.Warnings)
+}
@@ -0,0 +1,13 @@
+package config
+
+import "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/csvdialect"
+
+// AppsDialect returns the dialect of the apps CSV file
+func (c AppConfig) AppsDialect() (csvdialect.Dialect, error) {
+	return csvdialect.Parse(c.CSVDelimiter, c.CSVComment, c.CSVEncoding, c.CSVLazyQuotes, c.CSVTrimSpace, c.CSVBOM)
+}
+
+// ReviewsDialect returns the dialect of the reviews CSV file
+func (c AppConfig) ReviewsDialect() (csvdialect.Dialect, error) {
+	return csvdialect.Parse(c.ReviewDelimiter, c.ReviewComment, c.ReviewEncoding, c.ReviewLazyQuotes, c.ReviewTrimSpace, c.ReviewBOM)
+}
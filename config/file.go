@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// readFile reads a YAML, TOML or JSON config file into environment variables.
+// Unknown settings are returned as Errors alongside the known values.
+// Keys are setting names in any case, with dashes or underscores:
+//
+//	app_port: 3002
+//	log_ignore_paths: [/docs, /health/*]
+//	log_sample_rates: {2xx: 0.1, 5xx: 1}
+//
+// or in TOML:
+//
+//	app_port = 3002
+//	log_ignore_paths = ["/docs", "/health/*"]
+//	log_sample_rates = {2xx = 0.1, 5xx = 1}
+func readFile(path string) (map[string]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".yaml", ".yml", ".json", ".toml":
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml, .toml or .json", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if ext == ".toml" {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, key := range settingKeys() {
+		known[key] = true
+	}
+
+	var errs Errors
+	values := map[string]string{}
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := raw[name]
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q", path, name))
+			continue
+		}
+		values[key] = envValue(value)
+	}
+	if len(errs) > 0 {
+		return values, errs
+	}
+	return values, nil
+}
+
+// envValue formats a YAML or TOML value the way envconfig reads it: lists
+// as comma separated values and maps as comma separated key:value pairs
+func envValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, envValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[interface{}]interface{}:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, fmt.Sprintf("%v:%s", key, envValue(item)))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, key+":"+envValue(item))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadFileFormats(t *testing.T) {
+	want := map[string]string{
+		"APP_PORT":         "3002",
+		"LOG_IGNORE_PATHS": "/docs,/health/*",
+		"LOG_SAMPLE_RATES": "2xx:0.1,5xx:1",
+		"DEBUG":            "true",
+	}
+	files := map[string]string{
+		"config.yaml": "app_port: 3002\nlog-ignore-paths: [/docs, /health/*]\nlog_sample_rates: {2xx: 0.1, 5xx: 1}\ndebug: true\n",
+		"config.json": `{"APP_PORT": 3002, "log_ignore_paths": ["/docs", "/health/*"], "log_sample_rates": {"2xx": 0.1, "5xx": 1}, "debug": true}`,
+		"config.toml": "app_port = 3002\nlog-ignore-paths = [\"/docs\", \"/health/*\"]\nlog_sample_rates = {2xx = 0.1, 5xx = 1}\ndebug = true\n",
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		values, err := readFile(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, want) {
+			t.Errorf("%s = %v; want %v", name, values, want)
+		}
+	}
+}
+
+func TestReadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(unknown, []byte("app_port = 3002\nno_such_setting = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	values, err := readFile(unknown)
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || values["APP_PORT"] != "3002" {
+		t.Errorf("readFile with an unknown setting = %v, %v; want the known values and one error", values, err)
+	}
+
+	broken := filepath.Join(dir, "broken.toml")
+	if err := os.WriteFile(broken, []byte("app_port = \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFile(broken); err == nil {
+		t.Error("readFile of invalid TOML succeeded")
+	}
+	if _, err := readFile(filepath.Join(dir, "config.ini")); err == nil {
+		t.Error("readFile of an .ini file succeeded")
+	}
+}
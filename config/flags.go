@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvConfigFile names the config file when --config is not given
+const EnvConfigFile = "CONFIG_FILE"
+
+// FlagConfig is the flag naming the config file
+const FlagConfig = "config"
+
+// FileFlags returns the --config flag, which every command accepts
+func FileFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	flags.StringP(FlagConfig, "c", "", "YAML, TOML or JSON config file, overridden by the environment (env "+EnvConfigFile+")")
+	return flags
+}
+
+// Flags returns a flag per setting, named after its environment variable:
+// --app-port overrides APP_PORT. Values are parsed like the environment.
+func Flags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("settings", pflag.ContinueOnError)
+	fields := reflect.TypeOf(AppConfig{})
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		key := field.Tag.Get("envconfig")
+		if key == "" {
+			continue
+		}
+		flags.String(flagName(key), field.Tag.Get("default"), "overrides "+key)
+	}
+	return flags
+}
+
+// parseFlags picks the config flags out of args, ignoring the flags and
+// arguments that belong to the commands. It also reports whether help was
+// asked for, which needs no valid config.
+func parseFlags(args []string) (*pflag.FlagSet, bool, error) {
+	flags := Flags()
+	flags.AddFlagSet(FileFlags())
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+
+	// Help is printed by the command
+	err := flags.Parse(args)
+	help := errors.Is(err, pflag.ErrHelp)
+	if err != nil && !help {
+		return nil, false, err
+	}
+	return flags, help || flags.Arg(0) == "help", nil
+}
+
+// flagKeys maps flag names to the environment variables they override
+func flagKeys() map[string]string {
+	keys := map[string]string{}
+	for _, key := range settingKeys() {
+		keys[flagName(key)] = key
+	}
+	return keys
+}
+
+// settingKeys returns the environment variable of every setting
+func settingKeys() []string {
+	var keys []string
+	fields := reflect.TypeOf(AppConfig{})
+	for i := 0; i < fields.NumField(); i++ {
+		if key := fields.Field(i).Tag.Get("envconfig"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// flagName turns APP_PORT into app-port
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
@@ -1,17 +1,27 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/pflag"
 )
 
 // AllConfig variable of type AppConfig
 var AllConfig AppConfig
 
+var (
+	// loadArgs are the arguments of the last load, reused by Reload
+	loadArgs []string
+	// envSet are the variables the last load set from .env and the file
+	envSet = map[string]bool{}
+)
+
 // AppConfig type AppConfig
 type AppConfig struct {
 	IsDevelopment  bool   `envconfig:"IS_DEVELOPMENT"`
@@ -20,31 +30,261 @@ type AppConfig struct {
 	Port           string `envconfig:"APP_PORT"`
 	CSVFilePath    string `envconfig:"CSV_FILE_PATH"`
 	ReviewFilePath string `envconfig:"REVIEW_FILE_PATH"`
+
+	// CSV columns to read as another column, e.g. "Name:App,Rating Score:Rating".
+	// Files keep their own header names and order when rows are written.
+	CSVHeaderAliases map[string]string `envconfig:"CSV_HEADER_ALIASES"`
+
+	// Dialect of the apps CSV file, applied when it is read and written.
+	// Delimiter and comment are one character or "tab"; encoding is a label
+	// such as utf-8, latin1 or windows-1252, transcoded to and from UTF-8.
+	// A leading UTF-8 byte order mark is always stripped, CSV_BOM writes one.
+	CSVDelimiter  string `envconfig:"CSV_DELIMITER" default:","`
+	CSVComment    string `envconfig:"CSV_COMMENT"`
+	CSVLazyQuotes bool   `envconfig:"CSV_LAZY_QUOTES"`
+	CSVTrimSpace  bool   `envconfig:"CSV_TRIM_SPACE"`
+	CSVEncoding   string `envconfig:"CSV_ENCODING" default:"utf-8"`
+	CSVBOM        bool   `envconfig:"CSV_BOM"`
+
+	// Dialect of the reviews CSV file, same settings as the apps file
+	ReviewDelimiter  string `envconfig:"REVIEW_DELIMITER" default:","`
+	ReviewComment    string `envconfig:"REVIEW_COMMENT"`
+	ReviewLazyQuotes bool   `envconfig:"REVIEW_LAZY_QUOTES"`
+	ReviewTrimSpace  bool   `envconfig:"REVIEW_TRIM_SPACE"`
+	ReviewEncoding   string `envconfig:"REVIEW_ENCODING" default:"utf-8"`
+	ReviewBOM        bool   `envconfig:"REVIEW_BOM"`
+
+	// Goroutines decoding the rows of a CSV file while it is streamed, 0 for one per CPU
+	CSVParseWorkers int `envconfig:"CSV_PARSE_WORKERS" default:"0"`
+
+	// Error responses: "jsend" or "problem" (RFC 7807). Clients can always
+	// ask for problem+json through the Accept header.
+	ErrorFormat        string `envconfig:"ERROR_FORMAT" default:"jsend"`
+	ProblemTypeBaseURL string `envconfig:"PROBLEM_TYPE_BASE_URL"`
+
+	// Idempotency-Key support for POST endpoints
+	IdempotencyStorePath string        `envconfig:"IDEMPOTENCY_STORE_PATH" default:"data/idempotency.json"`
+	IdempotencyTTL       time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+
+	// What to do when an inserted row duplicates an existing one:
+	// "reject", "merge" (keep the most recently updated row) or "allow"
+	DuplicatePolicy string `envconfig:"DUPLICATE_POLICY" default:"reject"`
+
+	// Deleted rows are kept in the trash file until they are purged
+	TrashFilePath  string        `envconfig:"TRASH_FILE_PATH" default:"data/trash.json"`
+	TrashRetention time.Duration `envconfig:"TRASH_RETENTION" default:"720h"`
+
+	// CSV rows that fail to decode are skipped and kept here for review
+	QuarantineFilePath string `envconfig:"QUARANTINE_FILE_PATH" default:"data/quarantine.json"`
+
+	// Append-only JSON lines log of every mutation
+	AuditLogPath string `envconfig:"AUDIT_LOG_PATH" default:"data/audit.jsonl"`
+
+	// Row revisions backing history and as_of reads
+	RevisionFilePath string `envconfig:"REVISION_FILE_PATH" default:"data/revisions.jsonl"`
+
+	// Server-Sent Events change feed
+	EventsBufferSize int           `envconfig:"EVENTS_BUFFER_SIZE" default:"1000"`
+	EventsHeartbeat  time.Duration `envconfig:"EVENTS_HEARTBEAT" default:"15s"`
+
+	// Outbound webhooks
+	WebhookStorePath   string        `envconfig:"WEBHOOK_STORE_PATH" default:"data/webhooks.json"`
+	WebhookWorkers     int           `envconfig:"WEBHOOK_WORKERS" default:"4"`
+	WebhookQueueSize   int           `envconfig:"WEBHOOK_QUEUE_SIZE" default:"1000"`
+	WebhookMaxAttempts int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"5"`
+	WebhookBackoff     time.Duration `envconfig:"WEBHOOK_BACKOFF" default:"1s"`
+	WebhookMaxBackoff  time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF" default:"5m"`
+	WebhookTimeout     time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookLogSize     int           `envconfig:"WEBHOOK_LOG_SIZE" default:"100"`
+
+	// Listener: a Unix domain socket replaces the TCP host and port. A
+	// certificate and key enable TLS, a client CA bundle adds mutual TLS
+	// with TLSClientAuth "require" or "optional". Changed files are picked
+	// up every TLSReloadInterval, 0 disables reloading.
+	UnixSocketPath    string        `envconfig:"UNIX_SOCKET_PATH"`
+	UnixSocketMode    uint32        `envconfig:"UNIX_SOCKET_MODE" default:"0660"`
+	TLSCertFile       string        `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile   string        `envconfig:"TLS_CLIENT_CA_FILE"`
+	TLSClientAuth     string        `envconfig:"TLS_CLIENT_AUTH" default:"require"`
+	TLSMinVersion     string        `envconfig:"TLS_MIN_VERSION" default:"1.2"`
+	TLSReloadInterval time.Duration `envconfig:"TLS_RELOAD_INTERVAL" default:"30s"`
+
+	// Admin and webhook routes accept "Authorization: Bearer ADMIN_TOKEN" or
+	// a verified mutual TLS client certificate. With neither configured they
+	// are refused. X-Actor only labels changes, it grants no access.
+	AdminToken string `envconfig:"ADMIN_TOKEN"`
+
+	// Graceful shutdown: readiness fails for the drain delay before the
+	// server stops accepting connections, then in-flight requests and
+	// writes get the shutdown timeout to finish
+	ShutdownDrainDelay time.Duration `envconfig:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+	ShutdownTimeout    time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// Background jobs
+	JobsShutdownTimeout time.Duration `envconfig:"JOBS_SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// Scheduled maintenance as cron expressions, an empty schedule disables the task
+	ScheduleCompaction   string        `envconfig:"SCHEDULE_COMPACTION" default:"0 3 * * *"`
+	ScheduleSnapshot     string        `envconfig:"SCHEDULE_SNAPSHOT" default:"0 2 * * *"`
+	ScheduleTrashPurge   string        `envconfig:"SCHEDULE_TRASH_PURGE" default:"30 3 * * *"`
+	ScheduleStatsRefresh string        `envconfig:"SCHEDULE_STATS_REFRESH" default:"*/15 * * * *"`
+	ScheduleJitter       time.Duration `envconfig:"SCHEDULE_JITTER" default:"30s"`
+	SnapshotDir          string        `envconfig:"SNAPSHOT_DIR" default:"data/snapshots"`
+	SnapshotRetain       int           `envconfig:"SNAPSHOT_RETAIN" default:"7"`
+
+	// Log level: debug, info, warn or error. Empty uses debug with DEBUG
+	// set and info otherwise. It can also be changed through the admin API.
+	LogLevel string `envconfig:"LOG_LEVEL"`
+
+	// HTTP request logging
+	LogIgnorePaths   []string           `envconfig:"LOG_IGNORE_PATHS" default:"/docs,/assets/redoc.css,/assets/redoc.standalone.js,/assets/swagger.json,/favicon.ico,/health/*"`
+	LogRedactHeaders []string           `envconfig:"LOG_REDACT_HEADERS" default:"Authorization,Cookie,Set-Cookie,Proxy-Authorization,X-Api-Key"`
+	LogRedactFields  []string           `envconfig:"LOG_REDACT_FIELDS" default:"password,token,secret,api_key"`
+	LogMaxBodyBytes  int                `envconfig:"LOG_MAX_BODY_BYTES" default:"2048"`
+	LogSampleRates   map[string]float64 `envconfig:"LOG_SAMPLE_RATES" default:"2xx:1,3xx:1,4xx:1,5xx:1"`
+
+	// CORS, disabled without allowed origins. Empty allowed headers
+	// accept whatever the preflight request asks for.
+	CORSAllowOrigins []string      `envconfig:"CORS_ALLOW_ORIGINS"`
+	CORSAllowHeaders []string      `envconfig:"CORS_ALLOW_HEADERS"`
+	CORSMaxAge       time.Duration `envconfig:"CORS_MAX_AGE" default:"0s"`
+
+	// Requests per client IP and window on the API, 0 disables limiting
+	RateLimitMax    int           `envconfig:"RATE_LIMIT_MAX" default:"0"`
+	RateLimitWindow time.Duration `envconfig:"RATE_LIMIT_WINDOW" default:"1m"`
+
+	// OpenTelemetry tracing
+	TracingEnabled      bool    `envconfig:"TRACING_ENABLED"`
+	TracingExporter     string  `envconfig:"TRACING_EXPORTER" default:"stdout"`
+	TracingOTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	TracingOTLPInsecure bool    `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+	TracingFilePath     string  `envconfig:"TRACING_FILE_PATH" default:"traces.json"`
+	TracingSampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	TracingServiceName  string  `envconfig:"TRACING_SERVICE_NAME" default:"golang-api"`
+
+	// Sentry error reporting, disabled without a DSN. A sample rate of 0
+	// is treated by Sentry as 1, leave the DSN empty to report nothing.
+	SentryDSN          string        `envconfig:"SENTRY_DSN"`
+	SentryEnvironment  string        `envconfig:"SENTRY_ENVIRONMENT" default:"development"`
+	SentryRelease      string        `envconfig:"SENTRY_RELEASE"`
+	SentrySampleRate   float64       `envconfig:"SENTRY_SAMPLE_RATE" default:"1"`
+	SentryFlushTimeout time.Duration `envconfig:"SENTRY_FLUSH_TIMEOUT" default:"2s"`
 }
 
-// GetConfig Collects all configs
+// GetConfig Collects all configs from the environment and the config file
+// named by CONFIG_FILE, exiting when a setting is invalid
 func GetConfig() AppConfig {
-	err := godotenv.Load()
+	cfg, err := Load(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cfg
+}
+
+// Load collects all configs. Each setting is taken from, in order of
+// precedence: a command line flag in args, the environment (including
+// .env), the config file given by --config or CONFIG_FILE, and finally
+// its default. Every invalid setting is reported in the returned Errors,
+// unless args only ask for help.
+func Load(args []string) (AppConfig, error) {
+	// Variables set by the previous load are dropped so a reload sees
+	// edits to .env and the config file
+	for key := range envSet {
+		os.Unsetenv(key)
+	}
+	envSet = map[string]bool{}
+	loadArgs = args
+
+	dotenv, err := godotenv.Read()
 	if err != nil {
 		log.Println("warning .env file not found, scanning from OS ENV")
 	}
+	setEnvDefaults(dotenv)
+
+	flags, help, err := parseFlags(args)
+	if err != nil {
+		return AppConfig{}, err
+	}
+
+	var errs Errors
+	path := os.Getenv(EnvConfigFile)
+	if flag := flags.Lookup(FlagConfig); flag.Changed {
+		path = flag.Value.String()
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if !errors.As(err, &errs) && err != nil {
+			return AppConfig{}, err
+		}
+		// The file only fills in what the environment leaves unset
+		setEnvDefaults(values)
+	}
+
+	// Flags override the environment
+	keys := flagKeys()
+	flags.Visit(func(flag *pflag.Flag) {
+		if key, ok := keys[flag.Name]; ok {
+			os.Setenv(key, flag.Value.String())
+		}
+	})
 
 	AllConfig = AppConfig{}
+	errs = append(errs, process(&AllConfig)...)
+	errs = append(errs, Validate(AllConfig)...)
+	if len(errs) > 0 && !help {
+		return AllConfig, errs
+	}
+	return AllConfig, nil
+}
 
-	err = envconfig.Process("APP_PORT", &AllConfig)
-	if err != nil {
-		log.Fatal(err)
+// setEnvDefaults sets the variables the environment does not already have
+func setEnvDefaults(values map[string]string) {
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); !ok {
+			os.Setenv(key, value)
+			envSet[key] = true
+		}
 	}
+}
 
-	return AllConfig
+// process fills cfg from the environment. A value that does not parse is
+// recorded and its default used instead, so every bad value is reported
+// rather than only the first.
+func process(cfg *AppConfig) Errors {
+	var errs Errors
+	unset := map[string]string{}
+	defer func() {
+		for key, value := range unset {
+			os.Setenv(key, value)
+		}
+	}()
+
+	for {
+		err := envconfig.Process("", cfg)
+		if err == nil {
+			return errs
+		}
+
+		var parseErr *envconfig.ParseError
+		if !errors.As(err, &parseErr) {
+			return append(errs, err)
+		}
+		if _, seen := unset[parseErr.KeyName]; seen {
+			// A default that does not parse, nothing left to fall back on
+			return append(errs, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: invalid %s %q", parseErr.KeyName, parseErr.TypeName, parseErr.Value))
+		unset[parseErr.KeyName] = parseErr.Value
+		os.Unsetenv(parseErr.KeyName)
+	}
 }
 
-// GetConfigByName Collects all configs
+// GetConfigByName returns a single setting from the environment and .env
 func GetConfigByName(key string) string {
 	err := godotenv.Load()
-
 	if err != nil {
-		log.Fatal(err)
+		log.Println("warning .env file not found, scanning from OS ENV")
 	}
 
 	return os.Getenv(key)
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// reloadable are the settings Reload applies to the running server, the
+// others need a restart
+var reloadable = map[string]bool{
+	"DEBUG":              true,
+	"LOG_LEVEL":          true,
+	"LOG_IGNORE_PATHS":   true,
+	"LOG_REDACT_HEADERS": true,
+	"LOG_REDACT_FIELDS":  true,
+	"LOG_MAX_BODY_BYTES": true,
+	"LOG_SAMPLE_RATES":   true,
+	"CORS_ALLOW_ORIGINS": true,
+	"CORS_ALLOW_HEADERS": true,
+	"CORS_MAX_AGE":       true,
+	"RATE_LIMIT_MAX":     true,
+	"RATE_LIMIT_WINDOW":  true,
+}
+
+// Change is a reloadable setting that changed
+type Change struct {
+	Key    string      `json:"key"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ReloadHook applies reloaded settings. cfg is the config now in effect.
+type ReloadHook func(ctx context.Context, cfg AppConfig, changes []Change)
+
+var (
+	reloadHooks []ReloadHook
+	reloadMutex sync.Mutex
+)
+
+// OnReload registers hook to run after every reload that changed a setting
+func OnReload(hook ReloadHook) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload loads the config again with the arguments of the last Load and
+// applies the reloadable settings that changed. Changed settings that need
+// a restart are returned by key and left as they were. On error the
+// current config stays in effect.
+func Reload(ctx context.Context) ([]Change, []string, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	current := AllConfig
+	loaded, err := Load(loadArgs)
+	if err != nil {
+		AllConfig = current
+		return nil, nil, err
+	}
+
+	var changes []Change
+	var restart []string
+	next := reflect.ValueOf(&current).Elem()
+	fields := next.Type()
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).Tag.Get("envconfig")
+		before, after := next.Field(i), reflect.ValueOf(loaded).Field(i)
+		if key == "" || reflect.DeepEqual(before.Interface(), after.Interface()) {
+			continue
+		}
+		if !reloadable[key] {
+			restart = append(restart, key)
+			continue
+		}
+		changes = append(changes, Change{Key: key, Before: before.Interface(), After: after.Interface()})
+		before.Set(after)
+	}
+
+	AllConfig = current
+	if len(changes) > 0 {
+		for _, hook := range reloadHooks {
+			hook(ctx, current, changes)
+		}
+	}
+	return changes, restart, nil
+}
+
+// Changed reports whether changes include a setting whose key starts with
+// one of prefixes
+func Changed(changes []Change, prefixes ...string) bool {
+	for _, change := range changes {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(change.Key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/scheduler"
+)
+
+// Errors lists every invalid setting found while loading the config
+type Errors []error
+
+func (e Errors) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// checker collects the errors of failed checks
+type checker struct {
+	errs Errors
+}
+
+// check records an error formatted from format and args unless ok
+func (c *checker) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		c.errs = append(c.errs, fmt.Errorf(format, args...))
+	}
+}
+
+// ValidateServer checks the settings only the api command needs: where to
+// listen and which datasets to serve. Other commands take files as
+// arguments and run without them.
+func ValidateServer(cfg AppConfig) Errors {
+	var c checker
+	if cfg.UnixSocketPath == "" {
+		port, err := strconv.Atoi(cfg.Port)
+		c.check(err == nil && port > 0 && port < 65536, "APP_PORT: expected a port number, got %q", cfg.Port)
+	}
+	c.check(cfg.CSVFilePath != "", "CSV_FILE_PATH is required")
+	c.check(cfg.ReviewFilePath != "", "REVIEW_FILE_PATH is required")
+	return c.errs
+}
+
+// Validate checks the settings that would otherwise only fail once a
+// request or job needs them. See ValidateServer for those of the api
+// command.
+func Validate(cfg AppConfig) Errors {
+	var c checker
+	check := c.check
+
+	check(cfg.UnixSocketMode <= 0o777, "UNIX_SOCKET_MODE: expected permission bits, got %#o", cfg.UnixSocketMode)
+	for alias, column := range cfg.CSVHeaderAliases {
+		check(alias != "" && column != "", "CSV_HEADER_ALIASES: expected alias:column pairs, got %q:%q", alias, column)
+	}
+	_, err := cfg.AppsDialect()
+	check(err == nil, "CSV_* dialect: %v", err)
+	_, err = cfg.ReviewsDialect()
+	check(err == nil, "REVIEW_* dialect: %v", err)
+
+	if cfg.LogLevel != "" {
+		_, err := zapcore.ParseLevel(cfg.LogLevel)
+		check(err == nil, "LOG_LEVEL: expected debug, info, warn or error, got %q", cfg.LogLevel)
+	}
+	check(oneOf(cfg.ErrorFormat, "jsend", "problem"), "ERROR_FORMAT: expected jsend or problem, got %q", cfg.ErrorFormat)
+	check(oneOf(strings.ToLower(cfg.DuplicatePolicy), "reject", "merge", "allow"),
+		"DUPLICATE_POLICY: expected reject, merge or allow, got %q", cfg.DuplicatePolicy)
+	check(oneOf(strings.ToLower(cfg.TracingExporter), "", "stdout", "otlp", "file"),
+		"TRACING_EXPORTER: expected stdout, otlp or file, got %q", cfg.TracingExporter)
+
+	check(cfg.CSVParseWorkers >= 0, "CSV_PARSE_WORKERS: must not be negative")
+	check(cfg.EventsBufferSize > 0, "EVENTS_BUFFER_SIZE: must be positive")
+	check(cfg.WebhookWorkers > 0, "WEBHOOK_WORKERS: must be positive")
+	check(cfg.WebhookQueueSize > 0, "WEBHOOK_QUEUE_SIZE: must be positive")
+	check(cfg.WebhookMaxAttempts > 0, "WEBHOOK_MAX_ATTEMPTS: must be positive")
+	check(cfg.WebhookLogSize >= 0, "WEBHOOK_LOG_SIZE: must not be negative")
+	check(cfg.LogMaxBodyBytes >= 0, "LOG_MAX_BODY_BYTES: must not be negative")
+	check(cfg.SnapshotRetain >= 0, "SNAPSHOT_RETAIN: must not be negative")
+	check(cfg.RateLimitMax >= 0, "RATE_LIMIT_MAX: must not be negative")
+	check(cfg.RateLimitMax == 0 || cfg.RateLimitWindow > 0, "RATE_LIMIT_WINDOW: must be positive when RATE_LIMIT_MAX is set")
+
+	check(cfg.TracingSampleRatio >= 0 && cfg.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO: expected 0 to 1, got %v", cfg.TracingSampleRatio)
+	check(cfg.SentrySampleRate >= 0 && cfg.SentrySampleRate <= 1, "SENTRY_SAMPLE_RATE: expected 0 to 1, got %v", cfg.SentrySampleRate)
+	for class, rate := range cfg.LogSampleRates {
+		check(oneOf(class, "1xx", "2xx", "3xx", "4xx", "5xx"), "LOG_SAMPLE_RATES: unknown status class %q", class)
+		check(rate >= 0 && rate <= 1, "LOG_SAMPLE_RATES: expected 0 to 1 for %s, got %v", class, rate)
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		check(cfg.TLSCertFile != "" && cfg.TLSKeyFile != "", "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		check(oneOf(cfg.TLSMinVersion, "1.2", "1.3"), "TLS_MIN_VERSION: expected 1.2 or 1.3, got %q", cfg.TLSMinVersion)
+	} else {
+		check(cfg.TLSClientCAFile == "", "TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	if cfg.TLSClientCAFile != "" {
+		check(oneOf(cfg.TLSClientAuth, "require", "optional"), "TLS_CLIENT_AUTH: expected require or optional, got %q", cfg.TLSClientAuth)
+	}
+
+	schedules := []struct{ key, spec string }{
+		{"SCHEDULE_COMPACTION", cfg.ScheduleCompaction},
+		{"SCHEDULE_SNAPSHOT", cfg.ScheduleSnapshot},
+		{"SCHEDULE_TRASH_PURGE", cfg.ScheduleTrashPurge},
+		{"SCHEDULE_STATS_REFRESH", cfg.ScheduleStatsRefresh},
+	}
+	for _, schedule := range schedules {
+		if schedule.spec == "" {
+			continue
+		}
+		_, err := scheduler.Parse(schedule.spec)
+		check(err == nil, "%s: %v", schedule.key, err)
+	}
+
+	// No duration setting makes sense below zero
+	value := reflect.ValueOf(cfg)
+	for i := 0; i < value.NumField(); i++ {
+		if d, ok := value.Field(i).Interface().(time.Duration); ok {
+			check(d >= 0, "%s: must not be negative, got %s", value.Type().Field(i).Tag.Get("envconfig"), d)
+		}
+	}
+	return c.errs
+}
+
+// oneOf reports whether value is one of allowed
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
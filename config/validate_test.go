@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestServerSettingsAreOnlyCheckedForTheServer(t *testing.T) {
+	for _, key := range []string{"APP_PORT", "CSV_FILE_PATH", "REVIEW_FILE_PATH", "UNIX_SOCKET_PATH"} {
+		t.Setenv(key, "")
+	}
+	var cfg AppConfig
+	if errs := process(&cfg); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if errs := Validate(cfg); len(errs) > 0 {
+		t.Errorf("Validate without server settings: %v", errs)
+	}
+	if errs := ValidateServer(cfg); len(errs) != 3 {
+		t.Errorf("ValidateServer without server settings = %v; want APP_PORT, CSV_FILE_PATH and REVIEW_FILE_PATH", errs)
+	}
+
+	cfg.UnixSocketPath = "/run/app.sock"
+	cfg.CSVFilePath = "apps.csv"
+	cfg.ReviewFilePath = "reviews.csv"
+	if errs := ValidateServer(cfg); len(errs) > 0 {
+		t.Errorf("ValidateServer listening on a unix socket: %v", errs)
+	}
+	cfg.UnixSocketPath, cfg.Port = "", "70000"
+	if errs := ValidateServer(cfg); len(errs) != 1 {
+		t.Errorf("ValidateServer with port 70000 = %v; want the APP_PORT error", errs)
+	}
+}
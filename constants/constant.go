@@ -29,6 +29,18 @@ const (
 	ParamSentiment   = "sentiment"
 	ParamPolarityMin = "polarity_min"
 	ParamPolarityMax = "polarity_max"
+	ParamKind        = "kind"
+	ParamOlderThan   = "older_than"
+	ParamFrom        = "from"
+	ParamTo          = "to"
+	ParamActor       = "actor"
+	ParamAction      = "action"
+	ParamEntity      = "entity"
+	ParamAsOf        = "as_of"
+	ParamResource    = "resource"
+	ParamApp         = "app"
+	ParamLastEventID = "last_event_id"
+	ParamID          = "id"
 
 	// Default Query Values
 	DefaultAppName     = "10 Best Foods for You"
@@ -58,18 +70,145 @@ const (
 const (
 	//error constants
 
-	ErrInvalidAppNameFormat = "Invalid app name format"
-	ErrAppNotFound          = "App not found"
-	ErrDeleteApp            = "Failed to delete app"
-	AppDeletedSuccessfully  = "App deleted successfully"
-	ErrDecodingAppName      = "Error decoding app name"
-	ErrDeletingApp          = "Error deleting app"
-	LogDeletingApp          = "Deleting app with name"
-	AppNotFoundErrorMessage = "App not found"
-	ErrParsingCSV           = "Error parsing CSV file"
-	ErrCreatingCSVFile      = "Error creating CSV file"
-	ErrMarshallingCSVData   = "Error marshalling CSV data"
-	ErrReadingCSVRecords    = "Error reading CSV records"
-	ErrWritingCSVRecords    = "Error writing CSV records"
+	ErrInvalidAppNameFormat    = "Invalid app name format"
+	ErrAppNotFound             = "App not found"
+	ErrDeleteApp               = "Failed to delete app"
+	AppDeletedSuccessfully     = "App deleted successfully"
+	ErrDecodingAppName         = "Error decoding app name"
+	ErrDeletingApp             = "Error deleting app"
+	LogDeletingApp             = "Deleting app with name"
+	AppNotFoundErrorMessage    = "App not found"
+	ErrParsingCSV              = "Error parsing CSV file"
+	ErrCreatingCSVFile         = "Error creating CSV file"
+	ErrMarshallingCSVData      = "Error marshalling CSV data"
+	ErrReadingCSVRecords       = "Error reading CSV records"
+	ErrWritingCSVRecords       = "Error writing CSV records"
+	ErrInvalidPriceValue       = "Invalid price value"
+	ErrFilePathNotConfigured   = "CSV file path is not configured"
+	ErrReviewsNotFound         = "No reviews found"
+	ErrInvalidAppData          = "Invalid app data"
+	ErrInvalidReviewData       = "Invalid review data"
+	ErrAddApp                  = "Failed to add app"
+	ErrAddReview               = "Failed to add review"
+	ErrInternal                = "Internal server error"
+	ErrDuplicateApp            = "App already exists"
+	ErrDuplicateReview         = "Review already exists"
+	ErrDuplicateGroupNotFound  = "Duplicate group not found"
+	ErrFindDuplicates          = "Failed to find duplicates"
+	ErrMergeDuplicates         = "Failed to merge duplicates"
+	ErrInvalidRequestBody      = "Invalid request body"
+	ErrTrashEntryNotFound      = "Nothing to restore"
+	ErrInvalidTrashKind        = "Invalid trash kind, expected app or review"
+	ErrInvalidRetention        = "Invalid retention, expected a duration such as 720h"
+	ErrListTrash               = "Failed to list trash"
+	ErrRestoreApp              = "Failed to restore app"
+	ErrRestoreReviews          = "Failed to restore reviews"
+	ErrPurgeTrash              = "Failed to purge trash"
+	AppRestoredSuccessfully    = "App restored successfully"
+	ReviewsRestored            = "Reviews restored successfully"
+	ErrInvalidTimeFilter       = "Invalid time filter, expected an RFC 3339 timestamp"
+	ErrQueryAudit              = "Failed to query audit log"
+	ErrWritingAuditLog         = "Error writing audit log"
+	ErrShuttingDown            = "Server is shutting down, retry shortly"
+	ErrRateLimited             = "Too many requests, retry later"
+	ErrUnsupportedFormat       = "Unsupported file format, expected csv, json or ndjson"
+	ErrNoKnownColumns          = "CSV header has none of the expected columns, check the delimiter and CSV_HEADER_ALIASES"
+	ErrReloadDatasets          = "Failed to reload the datasets"
+	ErrImportIncomplete        = "Not every row can be imported, nothing was written"
+	ErrQuarantineUnrecoverable = "Dataset holds a quarantined row that cannot be written back, fix it in the CSV file first"
+	ErrInvalidEntity           = "Invalid entity, expected app or review"
+	ErrListQuarantine          = "Failed to list quarantined rows"
+	ErrInvalidLogLevel         = "Invalid level, expected debug, info, warn or error"
+	ErrReloadConfig            = "Failed to reload config"
+	ErrAdminUnauthorized       = "Admin routes need a bearer token or a client certificate"
+	ErrAdminDisabled           = "Admin routes are disabled, set ADMIN_TOKEN or enable mutual TLS"
+	DefaultAuditLimit          = "100"
+	ErrAppHistory              = "Failed to load app history"
+	ErrInvalidEventID          = "Invalid Last-Event-ID, expected an event id"
+	ErrCreateWebhook           = "Failed to create webhook"
+	ErrDeleteWebhook           = "Failed to delete webhook"
+	ErrRetryDeadLetter         = "Failed to retry dead letter"
+	WebhookDeletedSuccessfully = "Webhook deleted successfully"
+	DeadLetterQueued           = "Dead letter queued for delivery"
+	AppMergedSuccessfully      = "App merged with an existing entry"
+	ReviewMergedSuccessfully   = "Review matches an existing entry"
 	// ... other constants ...
 )
+
+// Request context
+const (
+	LocalRequestID    = "requestID"
+	LogFieldRequestID = "request_id"
+	LogFieldActor     = "actor"
+	HeaderActor       = "X-Actor"
+	HeaderLastEventID = "Last-Event-ID"
+	AnonymousActor    = "anonymous"
+	SchedulerActor    = "scheduler"
+	SignalActor       = "signal"
+	CLIActor          = "cli"
+)
+
+// Audit log entities besides the models
+const (
+	AuditEntityConfig   = "config"
+	AuditEntityLogLevel = "log_level"
+)
+
+// Idempotency headers
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Machine-readable error codes, stable across releases
+const (
+	CodeAppNotFound      = "app_not_found"
+	CodeReviewsNotFound  = "reviews_not_found"
+	CodeInvalidLimit     = "invalid_limit"
+	CodeInvalidPage      = "invalid_page"
+	CodeInvalidPrice     = "invalid_price"
+	CodeInvalidPolarity  = "invalid_polarity"
+	CodeInvalidAppName   = "invalid_app_name"
+	CodeInvalidBody      = "invalid_body"
+	CodeValidationFailed = "validation_failed"
+	CodeStorageFailure   = "storage_failure"
+	CodeIdempotencyBusy  = "idempotency_key_in_use"
+	CodeIdempotencyReuse = "idempotency_key_reused"
+	CodeIdempotencyKey   = "invalid_idempotency_key"
+	CodeInternal         = "internal_error"
+	CodeDuplicateApp     = "duplicate_app"
+	CodeDuplicateReview  = "duplicate_review"
+	CodeDuplicateGroup   = "duplicate_group_not_found"
+	CodeTrashNotFound    = "trash_entry_not_found"
+	CodeInvalidTrashKind = "invalid_trash_kind"
+	CodeInvalidEntity    = "invalid_entity"
+	CodeInvalidRetention = "invalid_retention"
+	CodeInvalidTime      = "invalid_time"
+	CodeInvalidEventID   = "invalid_event_id"
+	CodeWebhookNotFound  = "webhook_not_found"
+	CodeShuttingDown     = "shutting_down"
+	CodeRateLimited      = "rate_limited"
+	CodeInvalidLogLevel  = "invalid_log_level"
+	CodeInvalidConfig    = "invalid_config"
+	CodeUnauthorized     = "unauthorized"
+	CodeAdminDisabled    = "admin_disabled"
+	CodeQuarantineBlock  = "quarantine_unrecoverable"
+)
+
+// Health probe states
+const (
+	HealthOK       = "ok"
+	HealthDraining = "draining"
+	HealthNotReady = "not_ready"
+)
+
+// Error response formats
+const (
+	ErrorFormatJSend   = "jsend"
+	ErrorFormatProblem = "problem"
+	MIMEProblemJSON    = "application/problem+json"
+	MIMEEventStream    = "text/event-stream"
+)
+
+// How long an event stream client waits before reconnecting
+const EventsRetryMillis = 3000
@@ -0,0 +1,387 @@
+package v1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/audit"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/scheduler"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+type AdminController struct {
+	appModel        *models.AppModel
+	reviewModel     *models.ReviewModel
+	trashModel      *models.TrashModel
+	quarantineModel *models.QuarantineModel
+	statsModel      *models.StatsModel
+	auditLog        *audit.Log
+	scheduler       *scheduler.Scheduler
+	logger          *zap.Logger
+	config          config.AppConfig
+}
+
+// NewAdminController initializes the AdminController with dependencies.
+func NewAdminController(logger *zap.Logger, config config.AppConfig, auditLog *audit.Log, sched *scheduler.Scheduler) *AdminController {
+	return &AdminController{
+		appModel:        models.NewAppModel(logger, config),
+		reviewModel:     models.NewReviewModel(logger, config),
+		trashModel:      models.NewTrashModel(logger, config),
+		quarantineModel: models.NewQuarantineModel(logger, config),
+		statsModel:      models.NewStatsModel(logger, config),
+		auditLog:        auditLog,
+		scheduler:       sched,
+		logger:          logger,
+		config:          config,
+	}
+}
+
+// MergeDuplicatesRequest selects the duplicate group to merge
+type MergeDuplicatesRequest struct {
+	// Key of a single app group from the report, empty merges every group
+	Key string `json:"key"`
+}
+
+// @Summary Duplicate report
+// @Description List exact and near duplicate apps and identical reviews
+// @Tags admin
+// @Produce json
+// @Success 200 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/duplicates [get]
+func (adc *AdminController) ListDuplicates(c *fiber.Ctx) error {
+	apps, err := adc.appModel.FindDuplicates(c.UserContext())
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrFindDuplicates)
+	}
+
+	reviews, err := adc.reviewModel.FindDuplicateReviews(c.UserContext())
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrFindDuplicates)
+	}
+
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
+		"policy":  adc.config.DuplicatePolicy,
+		"apps":    apps,
+		"reviews": reviews,
+	})
+}
+
+// @Summary Merge duplicates
+// @Description Collapse duplicate apps into their most recently updated row and drop identical reviews
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body MergeDuplicatesRequest false "Group to merge"
+// @Success 200 {object} utils.JSONResponse
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/duplicates/merge [post]
+func (adc *AdminController) MergeDuplicates(c *fiber.Ctx) error {
+	var req MergeDuplicatesRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidBody, constants.ErrInvalidRequestBody)
+		}
+	}
+
+	appsRemoved, err := adc.appModel.MergeDuplicates(c.UserContext(), req.Key)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrMergeDuplicates)
+	}
+
+	// A key selects a single app group, reviews are only merged in bulk
+	reviewsRemoved := 0
+	if req.Key == "" {
+		reviewsRemoved, err = adc.reviewModel.MergeDuplicateReviews(c.UserContext())
+		if err != nil {
+			return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrMergeDuplicates)
+		}
+	}
+
+	utils.RequestLogger(c, adc.logger).Info("Merged duplicates",
+		zap.String("key", req.Key),
+		zap.Int("apps_removed", appsRemoved),
+		zap.Int("reviews_removed", reviewsRemoved),
+	)
+
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
+		"apps_removed":    appsRemoved,
+		"reviews_removed": reviewsRemoved,
+	})
+}
+
+// @Summary Trash listing
+// @Description List deleted apps and reviews, newest first
+// @Tags admin
+// @Produce json
+// @Param kind query string false "app or review"
+// @Success 200 {array} models.TrashEntry
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/trash [get]
+func (adc *AdminController) ListTrash(c *fiber.Ctx) error {
+	kind := c.Query(constants.ParamKind)
+	if kind != "" && kind != models.TrashKindApp && kind != models.TrashKindReview {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidTrashKind, constants.ErrInvalidTrashKind)
+	}
+
+	entries, err := adc.trashModel.ListTrash(c.UserContext(), kind)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrListTrash)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, entries)
+}
+
+// @Summary Quarantined rows
+// @Description List the CSV rows skipped by the last load of each dataset because they did not decode
+// @Tags admin
+// @Produce json
+// @Param entity query string false "app or review"
+// @Success 200 {array} models.QuarantinedRow
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/quarantine [get]
+func (adc *AdminController) ListQuarantine(c *fiber.Ctx) error {
+	entity := c.Query(constants.ParamEntity)
+	if entity != "" && entity != models.EntityApp && entity != models.EntityReview {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidEntity, constants.ErrInvalidEntity)
+	}
+
+	rows, err := adc.quarantineModel.ListQuarantine(c.UserContext(), entity)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrListQuarantine)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, rows)
+}
+
+// @Summary Purge trash
+// @Description Permanently remove trash entries older than the retention
+// @Tags admin
+// @Produce json
+// @Param older_than query string false "Duration overriding the configured retention, such as 24h"
+// @Success 200 {object} utils.JSONResponse
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/trash/purge [post]
+func (adc *AdminController) PurgeTrash(c *fiber.Ctx) error {
+	retention := adc.config.TrashRetention
+	if olderThan := c.Query(constants.ParamOlderThan); olderThan != "" {
+		parsed, err := time.ParseDuration(olderThan)
+		if err != nil || parsed < 0 {
+			return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidRetention, constants.ErrInvalidRetention)
+		}
+		retention = parsed
+	}
+
+	purged, err := adc.trashModel.Purge(c.UserContext(), retention)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrPurgeTrash)
+	}
+
+	utils.RequestLogger(c, adc.logger).Info("Purged trash",
+		zap.Duration("older_than", retention),
+		zap.Int("purged", purged),
+	)
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
+		"purged":     purged,
+		"older_than": retention.String(),
+	})
+}
+
+// @Summary Audit log
+// @Description List recorded mutations, newest first
+// @Tags admin
+// @Produce json
+// @Param from query string false "RFC 3339 start time"
+// @Param to query string false "RFC 3339 end time"
+// @Param actor query string false "Actor"
+// @Param action query string false "create, merge, delete, restore, purge, import"
+// @Param entity query string false "app, review or trash"
+// @Param limit query int false "Limit" default(100)
+// @Success 200 {array} audit.Entry
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/audit [get]
+func (adc *AdminController) ListAudit(c *fiber.Ctx) error {
+	filter := audit.Filter{
+		Actor:  c.Query(constants.ParamActor),
+		Action: c.Query(constants.ParamAction),
+		Entity: c.Query(constants.ParamEntity),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, constants.ParamFrom); err != nil {
+		return err
+	}
+	if filter.To, err = parseTimeQuery(c, constants.ParamTo); err != nil {
+		return err
+	}
+
+	filter.Limit, err = strconv.Atoi(c.Query(constants.Limit, constants.DefaultAuditLimit))
+	if err != nil || filter.Limit <= 0 {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidLimit, constants.ErrorInvalidLimit)
+	}
+
+	entries, err := adc.auditLog.Query(filter)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrQueryAudit)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, entries)
+}
+
+// @Summary Background jobs
+// @Description List every job pool with its queued and running jobs and the latest finished ones
+// @Tags admin
+// @Produce json
+// @Success 200 {array} routinewrapper.PoolStatus
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/jobs [get]
+func (adc *AdminController) ListJobs(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, fiber.StatusOK, routinewrapper.Pools())
+}
+
+// @Summary Scheduled tasks
+// @Description List the scheduled maintenance tasks with their next run and the outcome of the last one
+// @Tags admin
+// @Produce json
+// @Success 200 {array} scheduler.TaskStatus
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/schedule [get]
+func (adc *AdminController) ListSchedule(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, fiber.StatusOK, adc.scheduler.Status())
+}
+
+// @Summary Dataset statistics
+// @Description Aggregates over the apps and reviews, refreshed on a schedule
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.DatasetStats
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/stats [get]
+func (adc *AdminController) Stats(c *fiber.Ctx) error {
+	stats, err := adc.statsModel.Stats(c.UserContext())
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrorLoadingCache)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, stats)
+}
+
+// LogLevelRequest sets the root log level
+type LogLevelRequest struct {
+	// debug, info, warn or error
+	Level string `json:"level"`
+}
+
+// @Summary Log level
+// @Description Current level of the root logger
+// @Tags admin
+// @Produce json
+// @Success 200 {object} LogLevelRequest
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/log-level [get]
+func (adc *AdminController) GetLogLevel(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, fiber.StatusOK, LogLevelRequest{Level: logger.Level().String()})
+}
+
+// @Summary Change log level
+// @Description Change the level of the root logger without a restart. It lasts until the next restart or a reload that changes LOG_LEVEL or DEBUG.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body LogLevelRequest true "New level"
+// @Success 200 {object} LogLevelRequest
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/log-level [put]
+func (adc *AdminController) SetLogLevel(c *fiber.Ctx) error {
+	var req LogLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidBody, constants.ErrInvalidRequestBody)
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil || req.Level == "" {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidLogLevel, constants.ErrInvalidLogLevel)
+	}
+
+	before := logger.Level().Level()
+	logger.Level().SetLevel(level)
+	utils.RequestLogger(c, adc.logger).Info("Changed log level", zap.Stringer("from", before), zap.Stringer("to", level))
+
+	if before != level {
+		err = adc.auditLog.Append(audit.Entry{
+			Time:      time.Now().UTC(),
+			Actor:     utils.Actor(c),
+			RequestID: utils.RequestID(c),
+			Action:    models.ActionUpdate,
+			Entity:    constants.AuditEntityLogLevel,
+			Before:    before.String(),
+			After:     level.String(),
+		})
+		if err != nil {
+			utils.RequestLogger(c, adc.logger).Error(constants.ErrWritingAuditLog, zap.Error(err))
+		}
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, LogLevelRequest{Level: level.String()})
+}
+
+// @Summary Reload config
+// @Description Reload the config and the datasets like SIGHUP does. Logging, CORS and rate limit settings take effect, other changed settings are listed as needing a restart.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} utils.JSONResponse
+// @Failure 422 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/admin/config/reload [post]
+func (adc *AdminController) ReloadConfig(c *fiber.Ctx) error {
+	changes, restart, err := config.Reload(c.UserContext())
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusUnprocessableEntity, constants.CodeInvalidConfig, constants.ErrReloadConfig)
+	}
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	if restart == nil {
+		restart = []string{}
+	}
+
+	rows, err := models.ReloadDatasets(c.UserContext(), adc.logger, adc.config)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrReloadDatasets)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
+		"changes":          changes,
+		"restart_required": restart,
+		"datasets":         rows,
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"net/url"
 	"strconv"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
@@ -18,18 +17,20 @@ import (
 )
 
 type AppController struct {
-	appModel *models.AppModel
-	logger   *zap.Logger
-	config   config.AppConfig
+	appModel      *models.AppModel
+	revisionModel *models.RevisionModel
+	logger        *zap.Logger
+	config        config.AppConfig
 }
 
 // NewAppController initializes the AppController with dependencies.
 func NewAppController(logger *zap.Logger, config config.AppConfig) *AppController {
 	model := models.NewAppModel(logger, config)
 	return &AppController{
-		appModel: model,
-		logger:   logger,
-		config:   config,
+		appModel:      model,
+		revisionModel: models.NewRevisionModel(logger, config),
+		logger:        logger,
+		config:        config,
 	}
 }
 
@@ -41,45 +42,53 @@ func NewAppController(logger *zap.Logger, config config.AppConfig) *AppControlle
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Param priceFilter query string false "Price Filter"
+// @Param as_of query string false "RFC 3339 time to read the dataset at"
 // @Success 200 {array} models.App.
 // @Failure 400 {object} utils.JSONResponse
 // @Failure 500 {object} utils.JSONResponse
 // @Router /api/v1/apps [get]
 // ListApps handles the request for fetching all apps with pagination and filters.
 func (ac *AppController) ListApps(c *fiber.Ctx) error {
+	log := utils.RequestLogger(c, ac.logger)
+
 	limitStr := c.Query(constants.Limit, constants.DefaultLimit)
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil {
-		ac.logger.Error(constants.ErrorInvalidLimit+" - Parsing Error", zap.Error(err))
-		return utils.JSONError(c, fiber.StatusBadRequest, constants.ErrorInvalidLimit)
+		log.Error(constants.ErrorInvalidLimit+" - Parsing Error", zap.Error(err))
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidLimit, constants.ErrorInvalidLimit)
 	}
 	if limit <= 0 {
-		ac.logger.Error(constants.ErrorInvalidLimit+" - Invalid Value", zap.Int("limit", limit))
-		return utils.JSONError(c, fiber.StatusBadRequest, constants.ErrorInvalidLimit)
+		log.Error(constants.ErrorInvalidLimit+" - Invalid Value", zap.Int("limit", limit))
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidLimit, constants.ErrorInvalidLimit)
 	}
 
 	offsetStr := c.Query(constants.Offset, constants.DefaultOffset)
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil {
-		ac.logger.Error(constants.ErrorInvalidOffset+" - Parsing Error", zap.Error(err))
-		return utils.JSONFail(c, fiber.StatusBadRequest, constants.ErrorInvalidOffset)
+		log.Error(constants.ErrorInvalidOffset+" - Parsing Error", zap.Error(err))
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidPage, constants.ErrorInvalidOffset)
 	}
 	if offset < 0 {
-		ac.logger.Error(constants.ErrorInvalidOffset+" - Invalid Value", zap.Int("offset", offset))
-		return utils.JSONFail(c, fiber.StatusBadRequest, constants.ErrorInvalidOffset)
+		log.Error(constants.ErrorInvalidOffset+" - Invalid Value", zap.Int("offset", offset))
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidPage, constants.ErrorInvalidOffset)
 	}
 
 	// Extract filters from query parameters
 	priceFilter := c.Query(constants.ParamFilterPrice, "")
 
-	apps, err := ac.appModel.ListAllApps(limit, offset, priceFilter)
+	asOf, err := parseTimeQuery(c, constants.ParamAsOf)
 	if err != nil {
-		return utils.JSONFail(c, fiber.StatusBadRequest, err.Error())
+		return err
+	}
+
+	apps, err := ac.appModel.ListAllApps(c.UserContext(), limit, offset, priceFilter, asOf)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrorLoadingCache)
 	}
 
 	if len(apps) == 0 {
 		err = errors.New("no apps found with filters")
-		ac.logger.Error(err.Error(), zap.Error(err))
+		log.Error(err.Error(), zap.Error(err))
 		return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
 			"apps":    apps,
 			"total":   0,
@@ -98,6 +107,7 @@ func (ac *AppController) ListApps(c *fiber.Ctx) error {
 // @Param app body models.App true "App object to be added"
 // @Success 200 {object} utils.JSONResponse
 // @Failure 400 {object} utils.JSONResponse
+// @Failure 409 {object} utils.JSONResponse
 // @Failure 500 {object} utils.JSONResponse
 // @Router /api/v1/apps [post]
 
@@ -105,18 +115,21 @@ func (ac *AppController) AddApp(c *fiber.Ctx) error {
 	var app models.App
 	body := c.Body()
 	if err := json.Unmarshal(body, &app); err != nil {
-		return utils.JSONFail(c, fiber.StatusBadRequest, "Invalid app data")
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidBody, constants.ErrInvalidAppData)
 	}
 
-	// Validate the app struct
-	validate := validator.New() // Initialize validator here
-	if err := validate.Struct(app); err != nil {
-		ac.logger.Error("Validation error", zap.Error(err))
-		return utils.JSONFail(c, fiber.StatusBadRequest, utils.ValidatorErrorString(err)) // Use ValidateErrorString
+	// Validate the app struct with the shared validator
+	if err := utils.ValidateStruct(app); err != nil {
+		utils.RequestLogger(c, ac.logger).Error("Validation error", zap.Error(err))
+		return err
 	}
 
-	if err := ac.appModel.AddAppData(app); err != nil {
-		return utils.JSONFail(c, fiber.StatusInternalServerError, "Failed to add app")
+	result, err := ac.appModel.AddAppData(c.UserContext(), app)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrAddApp)
+	}
+	if result == models.InsertMerged {
+		return utils.JSONSuccess(c, fiber.StatusOK, constants.AppMergedSuccessfully)
 	}
 
 	return utils.JSONSuccess(c, fiber.StatusCreated, "App added successfully")
@@ -133,24 +146,75 @@ func (ac *AppController) AddApp(c *fiber.Ctx) error {
 // @Failure 500 {object} utils.JSONResponse
 // // @Router /api/v1/apps/{name} [delete]
 func (ac *AppController) DeleteApp(c *fiber.Ctx) error {
+	log := utils.RequestLogger(c, ac.logger)
+
 	// Get the URL-encoded app name parameter using the constant
 	encodedAppName := c.Params(constants.ParamAppName)
 	appName, err := url.QueryUnescape(encodedAppName)
 
 	if err != nil {
-		ac.logger.Error(constants.ErrDecodingAppName, zap.Error(err))
-		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrInvalidAppNameFormat)
+		log.Error(constants.ErrDecodingAppName, zap.Error(err))
+		return utils.NewAPIError(http.StatusBadRequest, constants.CodeInvalidAppName, constants.ErrInvalidAppNameFormat)
 	}
 
-	ac.logger.Info(constants.LogDeletingApp, zap.String(constants.ParamAppName, appName))
+	log.Info(constants.LogDeletingApp, zap.String(constants.ParamAppName, appName))
 
 	// Call the model's DeleteApp method with the decoded name
-	if err := ac.appModel.DeleteApp(appName); err != nil {
-		if err.Error() == constants.AppNotFoundErrorMessage {
-			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAppNotFound)
-		}
-		return utils.JSONFail(c, http.StatusInternalServerError, constants.ErrDeleteApp)
+	if err := ac.appModel.DeleteApp(c.UserContext(), appName); err != nil {
+		return utils.WrapAPIError(err, http.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrDeleteApp)
 	}
 
 	return utils.JSONSuccess(c, http.StatusOK, constants.AppDeletedSuccessfully)
 }
+
+// @Summary Restore a deleted app
+// @Description Put back the rows removed by the most recent delete of an app
+// @Tags apps
+// @Produce json
+// @Param name path string true "App name"
+// @Success 200 {object} utils.JSONSuccessResponse
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Router /api/v1/apps/{name}/restore [post]
+func (ac *AppController) RestoreApp(c *fiber.Ctx) error {
+	appName, err := url.QueryUnescape(c.Params(constants.ParamAppName))
+	if err != nil {
+		utils.RequestLogger(c, ac.logger).Error(constants.ErrDecodingAppName, zap.Error(err))
+		return utils.NewAPIError(http.StatusBadRequest, constants.CodeInvalidAppName, constants.ErrInvalidAppNameFormat)
+	}
+
+	restored, err := ac.appModel.RestoreApp(c.UserContext(), appName)
+	if err != nil {
+		return utils.WrapAPIError(err, http.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrRestoreApp)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, map[string]interface{}{
+		"message":  constants.AppRestoredSuccessfully,
+		"restored": restored,
+	})
+}
+
+// @Summary App history
+// @Description List the revisions of an app, newest first, with the fields each one changed
+// @Tags apps
+// @Produce json
+// @Param name path string true "App name"
+// @Success 200 {array} models.AppHistoryEntry
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Router /api/v1/apps/{name}/history [get]
+func (ac *AppController) AppHistory(c *fiber.Ctx) error {
+	appName, err := url.QueryUnescape(c.Params(constants.ParamAppName))
+	if err != nil {
+		utils.RequestLogger(c, ac.logger).Error(constants.ErrDecodingAppName, zap.Error(err))
+		return utils.NewAPIError(http.StatusBadRequest, constants.CodeInvalidAppName, constants.ErrInvalidAppNameFormat)
+	}
+
+	history, err := ac.revisionModel.AppHistory(c.UserContext(), appName)
+	if err != nil {
+		return utils.WrapAPIError(err, http.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrAppHistory)
+	}
+	return utils.JSONSuccess(c, http.StatusOK, history)
+}
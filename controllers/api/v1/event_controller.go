@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/events"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+type EventController struct {
+	broker *events.Broker
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewEventController initializes the EventController with dependencies.
+func NewEventController(logger *zap.Logger, config config.AppConfig, broker *events.Broker) *EventController {
+	return &EventController{
+		broker: broker,
+		logger: logger,
+		config: config,
+	}
+}
+
+// @Summary Change feed
+// @Description Stream app, review and dataset changes as Server-Sent Events
+// @Tags events
+// @Produce text/event-stream
+// @Param resource query string false "app, review or dataset"
+// @Param app query string false "App name"
+// @Param Last-Event-ID header string false "Resume after this event"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} utils.JSONResponse
+// @Router /api/v1/events [get]
+func (ec *EventController) Stream(c *fiber.Ctx) error {
+	resource := c.Query(constants.ParamResource)
+	app := c.Query(constants.ParamApp)
+
+	lastEventID := c.Get(constants.HeaderLastEventID, c.Query(constants.ParamLastEventID))
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidEventID, constants.ErrInvalidEventID)
+		}
+	}
+
+	backlog, complete, stream, cancel := ec.broker.Subscribe(lastID)
+	log := utils.RequestLogger(c, ec.logger)
+	// Closed when the server shuts down, open streams would hold it up
+	shutdown := c.Context().Done()
+	heartbeat := ec.config.EventsHeartbeat
+
+	c.Set(fiber.HeaderContentType, constants.MIMEEventStream)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		fmt.Fprintf(w, "retry: %d\n\n", constants.EventsRetryMillis)
+		if !complete {
+			writeEvent(w, events.Event{Type: events.TypeReset, Time: time.Now().UTC()})
+		}
+		for _, event := range backlog {
+			if event.Matches(resource, app) {
+				writeEvent(w, event)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-stream:
+				if !ok {
+					log.Debug("event subscriber dropped")
+					return
+				}
+				if !event.Matches(resource, app) {
+					continue
+				}
+				writeEvent(w, event)
+			case <-ticker.C:
+				// Comments keep proxies from closing an idle stream
+				fmt.Fprint(w, ": ping\n\n")
+			case <-shutdown:
+				return
+			}
+			// Flush fails once the client has gone away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// writeEvent writes event in the Server-Sent Events format
+func writeEvent(w *bufio.Writer, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if event.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/health"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+type HealthController struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewHealthController initializes the HealthController with dependencies.
+func NewHealthController(logger *zap.Logger, config config.AppConfig) *HealthController {
+	return &HealthController{
+		logger: logger,
+		config: config,
+	}
+}
+
+// @Summary Liveness probe
+// @Description Succeeds while the process is serving requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} utils.JSONResponse
+// @Router /health/live [get]
+func (hc *HealthController) Live(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{"status": constants.HealthOK})
+}
+
+// @Summary Readiness probe
+// @Description Fails once shutdown has started or when a data file cannot be reached
+// @Tags health
+// @Produce json
+// @Success 200 {object} utils.JSONResponse
+// @Failure 503 {object} utils.JSONResponse
+// @Router /health/ready [get]
+func (hc *HealthController) Ready(c *fiber.Ctx) error {
+	checks := map[string]string{}
+	ready := !health.Draining()
+	if ready {
+		checks["shutdown"] = constants.HealthOK
+	} else {
+		checks["shutdown"] = constants.HealthDraining
+	}
+
+	for name, path := range map[string]string{"apps_csv": hc.config.CSVFilePath, "reviews_csv": hc.config.ReviewFilePath} {
+		if _, err := os.Stat(path); err != nil {
+			checks[name] = err.Error()
+			ready = false
+			continue
+		}
+		checks[name] = constants.HealthOK
+	}
+
+	if !ready {
+		return utils.JSONFail(c, fiber.StatusServiceUnavailable, map[string]interface{}{
+			"status": constants.HealthNotReady,
+			"checks": checks,
+		})
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
+		"status": constants.HealthOK,
+		"checks": checks,
+	})
+}
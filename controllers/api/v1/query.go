@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// parseTimeQuery parses an optional RFC 3339 query parameter
+func parseTimeQuery(c *fiber.Ctx, param string) (time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidTime, constants.ErrInvalidTimeFilter)
+	}
+	return t, nil
+}
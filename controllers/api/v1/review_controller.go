@@ -8,9 +8,6 @@ import (
 	"net/url"
 	"strconv"
 
-	"errors"
-
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
@@ -28,7 +25,7 @@ type ReviewController struct {
 
 // NewReviewController initializes the ReviewController with dependencies.
 func NewReviewController(logger *zap.Logger, config config.AppConfig) *ReviewController {
-	model := models.NewReviewModel(config)
+	model := models.NewReviewModel(logger, config)
 	return &ReviewController{
 		reviewModel: model,
 		logger:      logger,
@@ -45,6 +42,7 @@ func NewReviewController(logger *zap.Logger, config config.AppConfig) *ReviewCon
 // @Param sentiment query string false "Sentiment" default("DefaultSentiment")
 // @Param polarityMin query number false "Minimum Polarity" default(-1)
 // @Param polarityMax query number false "Maximum Polarity" default(1)
+// @Param as_of query string false "RFC 3339 time to read the dataset at"
 // @Success 200 {array} models.Review
 // @Failure 400 {object} utils.JSONResponse
 // @Failure 500 {object} utils.JSONResponse
@@ -52,6 +50,8 @@ func NewReviewController(logger *zap.Logger, config config.AppConfig) *ReviewCon
 
 // ListReviews handles fetching reviews with filters.
 func (rc *ReviewController) ListReviews(c *fiber.Ctx) error {
+	log := utils.RequestLogger(c, rc.logger)
+
 	// Fetch query parameters with default values from constants
 	appName := c.Query(constants.ParamAppName, constants.DefaultAppName)
 	sentiment := c.Query(constants.ParamSentiment, constants.DefaultSentiment)
@@ -59,47 +59,42 @@ func (rc *ReviewController) ListReviews(c *fiber.Ctx) error {
 	// Parse polarity with more robust error handling
 	polarityMin, err := strconv.ParseFloat(c.Query(constants.ParamPolarityMin, constants.DefaultPolarityMin), 64)
 	if err != nil {
-		rc.logger.Error("Invalid polarity min",
+		log.Error("Invalid polarity min",
 			zap.String("value", c.Query(constants.ParamPolarityMin)),
 			zap.Error(err),
 		)
-		return utils.JSONFail(c, fiber.StatusBadRequest, constants.ErrorInvalidOffset)
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidPolarity, constants.ErrorInvalidPolarityMin)
 
 	}
 
 	polarityMax, err := strconv.ParseFloat(c.Query(constants.ParamPolarityMax, constants.DefaultPolarityMax), 64)
 	if err != nil {
-		rc.logger.Error("Invalid polarity max",
+		log.Error("Invalid polarity max",
 			zap.String("value", c.Query(constants.ParamPolarityMax)),
 			zap.Error(err),
 		)
 
-		return utils.JSONFail(c, fiber.StatusBadRequest, constants.ErrorInvalidOffset)
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidPolarity, constants.ErrorInvalidPolarityMax)
+	}
+
+	asOf, err := parseTimeQuery(c, constants.ParamAsOf)
+	if err != nil {
+		return err
 	}
 
 	// Log the parsed parameters
-	rc.logger.Info("Review Query Parameters",
+	log.Info("Review Query Parameters",
 		zap.String("app_name", appName),
 		zap.String("sentiment", sentiment),
 		zap.Float64("polarity_min", polarityMin),
 		zap.Float64("polarity_max", polarityMax),
 	)
 
-	// Fetch reviews from the model, passing the fiber context
-	reviews, err := rc.reviewModel.ListReviews(c, appName, sentiment, polarityMin, polarityMax)
+	// Fetch reviews from the model, passing the request context
+	reviews, err := rc.reviewModel.ListReviews(c.UserContext(), appName, sentiment, polarityMin, polarityMax, asOf)
 	if err != nil {
-
-		return utils.JSONFail(c, fiber.StatusBadRequest, err.Error())
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrParsingReviewsCSV)
 	}
-	if len(reviews) == 0 {
-		err = errors.New("No apps found with filters")
-		return utils.JSONSuccess(c, fiber.StatusOK, map[string]interface{}{
-			"reviews": reviews,
-			"total":   0,
-			"message": "No apps found matching the specified filters",
-		})
-	}
-
 	return utils.JSONSuccess(c, fiber.StatusOK, reviews)
 }
 
@@ -111,6 +106,7 @@ func (rc *ReviewController) ListReviews(c *fiber.Ctx) error {
 // @Param review body models.Review true "Review object to be added"
 // @Success 201 {object} utils.JSONSuccessResponse
 // @Failure 400 {object} utils.JSONResponse
+// @Failure 409 {object} utils.JSONResponse
 // @Failure 500 {object} utils.JSONResponse
 // @Router /api/v1/reviews [post]
 
@@ -118,17 +114,20 @@ func (rc *ReviewController) AddReview(c *fiber.Ctx) error {
 	var review models.Review
 	body := c.Body()
 	if err := json.Unmarshal(body, &review); err != nil {
-		return utils.JSONFail(c, fiber.StatusBadRequest, "Invalid review data")
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidBody, constants.ErrInvalidReviewData)
 	}
 
-	// Validate the review struct
-	validate := validator.New() // Initialize validator here
-	if err := validate.Struct(review); err != nil {
-		return utils.JSONFail(c, fiber.StatusBadRequest, utils.ValidatorErrorString(err))
+	// Validate the review struct with the shared validator
+	if err := utils.ValidateStruct(review); err != nil {
+		return err
 	}
 
-	if err := rc.reviewModel.AddReview(review); err != nil {
-		return utils.JSONFail(c, fiber.StatusInternalServerError, "Failed to add review")
+	result, err := rc.reviewModel.AddReview(c.UserContext(), review)
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrAddReview)
+	}
+	if result == models.InsertMerged {
+		return utils.JSONSuccess(c, fiber.StatusOK, constants.ReviewMergedSuccessfully)
 	}
 
 	return utils.JSONSuccess(c, fiber.StatusCreated, "Review added successfully")
@@ -151,18 +150,41 @@ func (rc *ReviewController) DeleteReview(c *fiber.Ctx) error {
 	// URL decode the app name (important for names with spaces and special characters)
 	appName, err := url.QueryUnescape(encodedAppName)
 	if err != nil {
-		rc.logger.Error(constants.ErrDecodingAppName, zap.Error(err))
-		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrInvalidAppNameFormat) // Use http.StatusBadRequest
-		
+		utils.RequestLogger(c, rc.logger).Error(constants.ErrDecodingAppName, zap.Error(err))
+		return utils.NewAPIError(http.StatusBadRequest, constants.CodeInvalidAppName, constants.ErrInvalidAppNameFormat)
 	}
 
-	
 	// Call the model's DeleteReview method with the decoded name
-	if err := rc.reviewModel.DeleteReview(appName); err != nil {
-		if err.Error() == constants.AppNotFoundErrorMessage {
-			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAppNotFound) // Use http.StatusBadRequest
-		}
-		return utils.JSONFail(c, http.StatusInternalServerError, constants.ErrDeleteReviews) // Use http.StatusInternalServerError
+	if err := rc.reviewModel.DeleteReview(c.UserContext(), appName); err != nil {
+		return utils.WrapAPIError(err, http.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrDeleteReviews)
 	}
 	return utils.JSONSuccess(c, http.StatusOK, constants.ReviewsDeletedSuccessfully) // Use http.StatusOK
 }
+
+// @Summary Restore deleted reviews
+// @Description Put back the reviews removed by the most recent delete for an app
+// @Tags reviews
+// @Produce json
+// @Param name path string true "App name"
+// @Success 200 {object} utils.JSONSuccessResponse
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Router /api/v1/review/{name}/restore [post]
+func (rc *ReviewController) RestoreReviews(c *fiber.Ctx) error {
+	appName, err := url.QueryUnescape(c.Params(constants.ParamAppName))
+	if err != nil {
+		utils.RequestLogger(c, rc.logger).Error(constants.ErrDecodingAppName, zap.Error(err))
+		return utils.NewAPIError(http.StatusBadRequest, constants.CodeInvalidAppName, constants.ErrInvalidAppNameFormat)
+	}
+
+	restored, err := rc.reviewModel.RestoreReviews(c.UserContext(), appName)
+	if err != nil {
+		return utils.WrapAPIError(err, http.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrRestoreReviews)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, map[string]interface{}{
+		"message":  constants.ReviewsRestored,
+		"restored": restored,
+	})
+}
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/webhook"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+type WebhookController struct {
+	store      *webhook.Store
+	dispatcher *webhook.Dispatcher
+	logger     *zap.Logger
+	config     config.AppConfig
+}
+
+// NewWebhookController initializes the WebhookController with dependencies.
+func NewWebhookController(logger *zap.Logger, config config.AppConfig, store *webhook.Store, dispatcher *webhook.Dispatcher) *WebhookController {
+	return &WebhookController{
+		store:      store,
+		dispatcher: dispatcher,
+		logger:     logger,
+		config:     config,
+	}
+}
+
+// CreateWebhookRequest is the body of a new subscription
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,http_url"`
+	// Event types such as "review.created", "review.*" or "*"
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	// Only deliver events for these apps, empty delivers for every app
+	Apps []string `json:"apps"`
+	// Signing secret, generated when empty
+	Secret string `json:"secret"`
+}
+
+// @Summary List webhooks
+// @Description List webhook subscriptions, secrets are not returned
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} webhook.Subscription
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks [get]
+func (wc *WebhookController) ListWebhooks(c *fiber.Ctx) error {
+	subs := wc.store.Subscriptions()
+	redacted := make([]webhook.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		redacted = append(redacted, sub.Redacted())
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, redacted)
+}
+
+// @Summary Create a webhook
+// @Description Subscribe a URL to change events. The secret is only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body CreateWebhookRequest true "Subscription"
+// @Success 201 {object} webhook.Subscription
+// @Failure 400 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks [post]
+func (wc *WebhookController) CreateWebhook(c *fiber.Ctx) error {
+	var req CreateWebhookRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return utils.NewAPIError(fiber.StatusBadRequest, constants.CodeInvalidBody, constants.ErrInvalidRequestBody)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeInternal, constants.ErrCreateWebhook)
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+
+	sub := webhook.Subscription{
+		ID:        uuid.NewString(),
+		URL:       req.URL,
+		Events:    req.Events,
+		Apps:      req.Apps,
+		Secret:    req.Secret,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: utils.Actor(c),
+	}
+	if err := wc.store.AddSubscription(sub); err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrCreateWebhook)
+	}
+
+	utils.RequestLogger(c, wc.logger).Info("Created webhook",
+		zap.String("webhook", sub.ID),
+		zap.String("url", sub.URL),
+		zap.Strings("events", sub.Events),
+	)
+	return utils.JSONSuccess(c, fiber.StatusCreated, sub)
+}
+
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} webhook.Subscription
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks/{id} [get]
+func (wc *WebhookController) GetWebhook(c *fiber.Ctx) error {
+	sub, err := wc.store.Subscription(c.Params(constants.ParamID))
+	if err != nil {
+		return err
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, sub.Redacted())
+}
+
+// @Summary Delete a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} utils.JSONSuccessResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks/{id} [delete]
+func (wc *WebhookController) DeleteWebhook(c *fiber.Ctx) error {
+	if err := wc.store.DeleteSubscription(c.Params(constants.ParamID)); err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrDeleteWebhook)
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, constants.WebhookDeletedSuccessfully)
+}
+
+// @Summary Webhook deliveries
+// @Description List the latest delivery attempts of a webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} webhook.Delivery
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (wc *WebhookController) ListDeliveries(c *fiber.Ctx) error {
+	sub, err := wc.store.Subscription(c.Params(constants.ParamID))
+	if err != nil {
+		return err
+	}
+	return utils.JSONSuccess(c, fiber.StatusOK, wc.dispatcher.Deliveries(sub.ID))
+}
+
+// @Summary Dead letters
+// @Description List deliveries that failed every attempt
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} webhook.DeadLetter
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks/dead-letters [get]
+func (wc *WebhookController) ListDeadLetters(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, fiber.StatusOK, wc.store.DeadLetters())
+}
+
+// @Summary Redeliver a dead letter
+// @Description Queue a dead-lettered delivery again
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Dead letter ID"
+// @Success 202 {object} utils.JSONSuccessResponse
+// @Failure 404 {object} utils.JSONResponse
+// @Failure 500 {object} utils.JSONResponse
+// @Failure 401 {object} utils.JSONResponse
+// @Failure 403 {object} utils.JSONResponse
+// @Router /api/v1/webhooks/dead-letters/{id}/retry [post]
+func (wc *WebhookController) RetryDeadLetter(c *fiber.Ctx) error {
+	letter, err := wc.store.TakeDeadLetter(c.Params(constants.ParamID))
+	if err != nil {
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrRetryDeadLetter)
+	}
+	if err := wc.dispatcher.Redeliver(letter); err != nil {
+		// The letter stays dead until it is queued
+		if putErr := wc.store.AddDeadLetter(letter); putErr != nil {
+			utils.RequestLogger(c, wc.logger).Error("error putting back webhook dead letter", zap.String("id", letter.ID), zap.Error(putErr))
+		}
+		return utils.WrapAPIError(err, fiber.StatusInternalServerError, constants.CodeStorageFailure, constants.ErrRetryDeadLetter)
+	}
+	return utils.JSONSuccess(c, fiber.StatusAccepted, constants.DeadLetterQueued)
+}
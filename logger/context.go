@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// ctxKey is the unexported key under which a request-scoped logger is stored
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx carrying the given logger.
+func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by WithContext.
+// Falls back to the given logger when ctx carries none.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return fallback
+}
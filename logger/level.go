@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level returns the level of the root logger. Setting it changes what the
+// root logger and every logger derived from it write, without a restart.
+func Level() zap.AtomicLevel {
+	return zapServerConfig.Level
+}
+
+// ConfiguredLevel returns the level named by name, or debug when name is
+// empty and debug is set, info otherwise
+func ConfiguredLevel(name string, debug bool) (zapcore.Level, error) {
+	if name != "" {
+		return zapcore.ParseLevel(name)
+	}
+	if debug {
+		return zapcore.DebugLevel, nil
+	}
+	return zapcore.InfoLevel, nil
+}
@@ -36,19 +36,25 @@ var zapServerConfig = zap.Config{
 // Supports toggling debug level and development mode formatting.
 //
 // Parameters:
+//   - level: log level name, empty to pick it from debug
 //   - debug: enables debug-level logging
 //   - development: toggles human-friendly colored console output
 //
 // Returns:
 //   - *zap.Logger: configured logger
 //   - error: any error during logger construction
-func NewRootLogger(debug, development bool) (*zap.Logger, error) {
+func NewRootLogger(level string, debug, development bool) (*zap.Logger, error) {
 	var err error
 	var logger *zap.Logger
 
+	// The level stays adjustable at runtime through Level
+	lvl, err := ConfiguredLevel(level, debug)
+	if err != nil {
+		return nil, err
+	}
+	zapServerConfig.Level.SetLevel(lvl)
+
 	if debug {
-		// Enable debug logging level
-		zapServerConfig.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
 		if !development {
 			// Debug mode with JSON encoding (production)
 			return zapServerConfig.Build(zap.AddStacktrace(zap.ErrorLevel), zap.AddCaller())
@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ToStderr returns logger writing to standard error instead, for commands
+// whose standard output is data. The level stays the root logger level.
+func ToStderr(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		encoder := zapcore.NewJSONEncoder(zapServerConfig.EncoderConfig)
+		if zapServerConfig.Encoding == "console" {
+			encoder = zapcore.NewConsoleEncoder(zapServerConfig.EncoderConfig)
+		}
+		return zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zapServerConfig.Level)
+	}))
+}
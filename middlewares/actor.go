@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Actors end up in the trash and in logs, so only plain identifiers are accepted
+var actorPattern = regexp.MustCompile(`^[A-Za-z0-9._@:\-]{1,128}$`)
+
+// Characters a certificate name may hold that actors may not
+var actorUnsafe = regexp.MustCompile(`[^A-Za-z0-9._@:\-]`)
+
+// Actor records who is making the request so models can stamp the changes
+// they make. A verified mutual TLS client certificate names the actor and
+// cannot be overridden; otherwise the X-Actor header does. Requests with
+// neither are made by the anonymous actor.
+func Actor(log *zap.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		actor := ctx.Get(constants.HeaderActor)
+		if identity := utils.ClientIdentity(ctx); identity != "" {
+			actor = actorUnsafe.ReplaceAllString(identity, "_")
+		}
+		if !actorPattern.MatchString(actor) {
+			actor = constants.AnonymousActor
+		}
+
+		reqLogger := logger.FromContext(ctx.UserContext(), log).With(zap.String(constants.LogFieldActor, actor))
+		userCtx := utils.ContextWithActor(ctx.UserContext(), actor)
+		ctx.SetUserContext(logger.WithContext(userCtx, reqLogger))
+
+		return ctx.Next()
+	}
+}
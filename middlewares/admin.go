@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// AdminOnly guards the routes that act on the whole dataset, the server
+// settings and webhooks. A verified mutual TLS client certificate or the
+// ADMIN_TOKEN bearer token lets a request through. The X-Actor header only
+// names who made a change and is not trusted for access. Without a token
+// or a client CA configured the routes are refused.
+func AdminOnly(logger *zap.Logger, cfg config.AppConfig) fiber.Handler {
+	token := []byte(cfg.AdminToken)
+	mutualTLS := cfg.TLSClientCAFile != ""
+
+	return func(ctx *fiber.Ctx) error {
+		if !mutualTLS && len(token) == 0 {
+			return utils.NewAPIError(fiber.StatusForbidden, constants.CodeAdminDisabled, constants.ErrAdminDisabled)
+		}
+		if mutualTLS && utils.ClientIdentity(ctx) != "" {
+			return ctx.Next()
+		}
+
+		bearer, ok := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if ok && len(token) > 0 && subtle.ConstantTimeCompare([]byte(bearer), token) == 1 {
+			return ctx.Next()
+		}
+
+		utils.RequestLogger(ctx, logger).Warn("unauthorized admin request", zap.String("path", ctx.Path()))
+		ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return utils.NewAPIError(fiber.StatusUnauthorized, constants.CodeUnauthorized, constants.ErrAdminUnauthorized)
+	}
+}
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestAdminOnly(t *testing.T) {
+	newApp := func(cfg config.AppConfig) *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(zap.NewNop(), cfg)})
+		app.Post("/admin/reload", AdminOnly(zap.NewNop(), cfg), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+	call := func(app *fiber.App, authorization, actor string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/admin/reload", nil)
+		if authorization != "" {
+			req.Header.Set(fiber.HeaderAuthorization, authorization)
+		}
+		if actor != "" {
+			req.Header.Set("X-Actor", actor)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	disabled := newApp(config.AppConfig{})
+	if status := call(disabled, "Bearer anything", "admin"); status != fiber.StatusForbidden {
+		t.Errorf("without ADMIN_TOKEN status = %d; want 403", status)
+	}
+
+	guarded := newApp(config.AppConfig{AdminToken: "secret"})
+	tests := []struct {
+		name          string
+		authorization string
+		actor         string
+		want          int
+	}{
+		{"token", "Bearer secret", "", fiber.StatusNoContent},
+		{"wrong token", "Bearer guess", "", fiber.StatusUnauthorized},
+		{"no scheme", "secret", "", fiber.StatusUnauthorized},
+		{"missing", "", "", fiber.StatusUnauthorized},
+		{"actor only", "", "admin", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if status := call(guarded, tt.authorization, tt.actor); status != tt.want {
+			t.Errorf("%s: status = %d; want %d", tt.name, status, tt.want)
+		}
+	}
+}
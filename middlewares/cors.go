@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+// CORS answers cross-origin requests from CORS_ALLOW_ORIGINS and does
+// nothing when no origin is allowed. It follows config reloads.
+func CORS(cfg config.AppConfig) fiber.Handler {
+	return reloadable(cfg, func(cfg config.AppConfig) fiber.Handler {
+		if len(cfg.CORSAllowOrigins) == 0 {
+			return next
+		}
+		return cors.New(cors.Config{
+			AllowOrigins: strings.Join(cfg.CORSAllowOrigins, ","),
+			AllowHeaders: strings.Join(cfg.CORSAllowHeaders, ","),
+			MaxAge:       int(cfg.CORSMaxAge.Seconds()),
+		})
+	}, "CORS_")
+}
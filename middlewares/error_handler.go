@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/idempotency"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/webhook"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// sentinelErrors maps model errors to the status and code they are reported with
+var sentinelErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{models.ErrAppNotFound, fiber.StatusNotFound, constants.CodeAppNotFound},
+	{models.ErrReviewsNotFound, fiber.StatusNotFound, constants.CodeReviewsNotFound},
+	{models.ErrInvalidPrice, fiber.StatusBadRequest, constants.CodeInvalidPrice},
+	{models.ErrDuplicateApp, fiber.StatusConflict, constants.CodeDuplicateApp},
+	{models.ErrDuplicateReview, fiber.StatusConflict, constants.CodeDuplicateReview},
+	{models.ErrDuplicateGroupNotFound, fiber.StatusNotFound, constants.CodeDuplicateGroup},
+	{models.ErrTrashEntryNotFound, fiber.StatusNotFound, constants.CodeTrashNotFound},
+	{webhook.ErrNotFound, fiber.StatusNotFound, constants.CodeWebhookNotFound},
+	{models.ErrShuttingDown, fiber.StatusServiceUnavailable, constants.CodeShuttingDown},
+	{models.ErrQuarantineUnrecoverable, fiber.StatusConflict, constants.CodeQuarantineBlock},
+	{idempotency.ErrKeyInFlight, fiber.StatusConflict, constants.CodeIdempotencyBusy},
+	{idempotency.ErrKeyReused, fiber.StatusUnprocessableEntity, constants.CodeIdempotencyReuse},
+	{idempotency.ErrKeyTooLong, fiber.StatusBadRequest, constants.CodeIdempotencyKey},
+}
+
+// ErrorHandler is the fiber error handler. Handlers return errors and this
+// turns them into a jsend or, when configured or requested through the
+// Accept header, an application/problem+json response. Server errors are
+// reported to Sentry.
+func ErrorHandler(logger *zap.Logger, cfg config.AppConfig) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		resolved := resolveError(err)
+
+		if resolved.status >= fiber.StatusInternalServerError {
+			utils.RequestLogger(c, logger).Error("request failed",
+				zap.Int("status", resolved.status),
+				zap.String("code", resolved.code),
+				zap.Error(err),
+			)
+			captureError(c, err)
+		}
+
+		if cfg.ErrorFormat == constants.ErrorFormatProblem || utils.AcceptsProblem(c) {
+			return utils.JSONProblem(c, resolved.status, resolved.code, resolved.message, cfg.ProblemTypeBaseURL, resolved.details)
+		}
+		if resolved.status >= fiber.StatusInternalServerError {
+			return utils.JSONError(c, resolved.status, resolved.message)
+		}
+		if resolved.details != nil {
+			return utils.JSONFail(c, resolved.status, map[string]interface{}{
+				"message": resolved.message,
+				"errors":  resolved.details,
+			})
+		}
+		return utils.JSONFail(c, resolved.status, resolved.message)
+	}
+}
+
+// resolvedError is the client facing description of an error
+type resolvedError struct {
+	status  int
+	code    string
+	message string
+	details interface{}
+}
+
+// resolveError finds the status, code and client facing message for err
+func resolveError(err error) resolvedError {
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel.err) {
+			return resolvedError{sentinel.status, sentinel.code, sentinelMessage(err, sentinel.err), nil}
+		}
+	}
+
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return resolvedError{fiber.StatusBadRequest, constants.CodeValidationFailed, validationErr.Error(), validationErr.Fields}
+	}
+
+	// Every invalid setting of a rejected config reload
+	var configErrs config.Errors
+	if errors.As(err, &configErrs) {
+		details := make([]string, 0, len(configErrs))
+		for _, e := range configErrs {
+			details = append(details, e.Error())
+		}
+		return resolvedError{fiber.StatusUnprocessableEntity, constants.CodeInvalidConfig, constants.ErrReloadConfig, details}
+	}
+
+	var apiErr *utils.APIError
+	if errors.As(err, &apiErr) {
+		return resolvedError{apiErr.Status, apiErr.Code, apiErr.Message, nil}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return resolvedError{fiberErr.Code, statusCode(fiberErr.Code), fiberErr.Message, nil}
+	}
+
+	return resolvedError{fiber.StatusInternalServerError, constants.CodeInternal, constants.ErrInternal, nil}
+}
+
+// sentinelMessage returns the most specific message for a sentinel match,
+// the text of the error that wrapped it if any
+func sentinelMessage(err, sentinel error) string {
+	var apiErr *utils.APIError
+	if errors.As(err, &apiErr) && apiErr.Err != nil {
+		err = apiErr.Err
+	}
+	if err.Error() != "" {
+		return err.Error()
+	}
+	return sentinel.Error()
+}
+
+// statusCode derives an error code such as "not_found" from a status
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return constants.CodeInternal
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
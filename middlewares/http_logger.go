@@ -1,54 +1,121 @@
 package middlewares
 
 import (
+	"context"
+	"math/rand"
 	"strings"
+	"sync/atomic"
 
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
 	pMetrics "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/prometheus"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
 	"github.com/gofiber/fiber/v2"
-	"github.com/samber/lo"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var (
-	zapCoreField []zapcore.Field
-	// Add path that needs to excluded from logging
-	ignorePathList = []string{
-		"/docs",
-		"/assets/redoc.css",
-		"/assets/redoc.standalone.js",
-		"/assets/swagger.json",
-		"/favicon.ico",
+// httpLogOptions is the request logging policy derived from AppConfig
+type httpLogOptions struct {
+	ignorePaths   []string
+	ignorePrefix  []string
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+	maxBodyBytes  int
+	sampleRates   map[string]float64
+}
+
+// newHTTPLogOptions builds the logging policy from config.
+// Ignore paths ending in "*" match every path with that prefix.
+func newHTTPLogOptions(cfg config.AppConfig) httpLogOptions {
+	opts := httpLogOptions{
+		redactHeaders: lowerSet(cfg.LogRedactHeaders),
+		redactFields:  lowerSet(cfg.LogRedactFields),
+		maxBodyBytes:  cfg.LogMaxBodyBytes,
+		sampleRates:   cfg.LogSampleRates,
 	}
-)
+	for _, path := range cfg.LogIgnorePaths {
+		path = strings.TrimSpace(path)
+		if strings.HasSuffix(path, "*") {
+			opts.ignorePrefix = append(opts.ignorePrefix, strings.TrimSuffix(path, "*"))
+		} else if path != "" {
+			opts.ignorePaths = append(opts.ignorePaths, path)
+		}
+	}
+	return opts
+}
+
+// ignored reports whether requests to path should not be logged
+func (o httpLogOptions) ignored(path string) bool {
+	for _, p := range o.ignorePaths {
+		if p == path {
+			return true
+		}
+	}
+	for _, p := range o.ignorePrefix {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled decides whether a response with the given status is logged.
+// Status classes without a configured rate are always logged.
+func (o httpLogOptions) sampled(status int) bool {
+	rate, ok := o.sampleRates[statusClass(status)]
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// LogHandler will log each request. The logging settings follow config reloads.
+func LogHandler(logger *zap.Logger, pMetrics *pMetrics.PrometheusMetrics, cfg config.AppConfig) fiber.Handler {
+	var current atomic.Pointer[httpLogOptions]
+	initial := newHTTPLogOptions(cfg)
+	current.Store(&initial)
+	config.OnReload(func(_ context.Context, cfg config.AppConfig, changes []config.Change) {
+		if config.Changed(changes, "LOG_") {
+			opts := newHTTPLogOptions(cfg)
+			current.Store(&opts)
+		}
+	})
 
-// Handler will log each request
-func LogHandler(logger *zap.Logger, pMetrics *pMetrics.PrometheusMetrics) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		err := ctx.Next()
-		if err != nil {
-			return err
+		opts := current.Load()
+
+		if err := ctx.Next(); err != nil {
+			// Build the error response now so it is logged and counted
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				return err
+			}
 		}
 
-		exits := lo.Contains(ignorePathList, ctx.Path()) || strings.HasPrefix(string(ctx.Response().Header.ContentType()), "image/") || strings.HasPrefix(string(ctx.Response().Header.ContentType()), "text/")
-		if !exits {
-			zapCoreField = []zapcore.Field{
+		status := ctx.Response().Header.StatusCode()
+		contentType := string(ctx.Response().Header.ContentType())
+		exits := opts.ignored(ctx.Path()) || strings.HasPrefix(contentType, "image/") || strings.HasPrefix(contentType, "text/")
+		if !exits && opts.sampled(status) {
+			// Fields are built per request, handlers run concurrently
+			fields := []zapcore.Field{
 				zap.String("host", ctx.Hostname()),
 				zap.String("method", string(ctx.Request().Header.Method())),
 				zap.String("uri", ctx.BaseURL()),
+				zap.String("path", ctx.Path()),
 				zap.String("protocol", ctx.Protocol()),
 				zap.String("username", string(ctx.Request().URI().Username())),
-				zap.String("requestHeaders", string(ctx.Request().Header.Header())),
-				zap.String("responseHeaders", string(ctx.Response().Header.Header())),
-				zap.String("request", string(ctx.Request().Body())),
-				zap.String("response", ctx.Response().String()),
-				zap.Int("status", ctx.Response().Header.StatusCode()),
+				zap.Any("requestHeaders", opts.requestHeaders(ctx)),
+				zap.Any("responseHeaders", opts.responseHeaders(ctx)),
+				zap.String("request", opts.body(ctx.Request().Body())),
+				zap.String("response", opts.body(ctx.Response().Body())),
+				zap.Int("status", status),
 				zap.Int("size", ctx.Response().Header.ContentLength()),
 			}
-			if ctx.Response().Header.StatusCode() >= 100 && ctx.Response().Header.StatusCode() <= 399 {
-				logger.Debug("Handled successful request", zapCoreField...)
+
+			log := utils.RequestLogger(ctx, logger)
+			if status >= 100 && status <= 399 {
+				log.Debug("Handled successful request", fields...)
 			} else {
-				logger.Error("handled error request", zapCoreField...)
+				log.Error("handled error request", fields...)
 			}
 		}
 
@@ -67,3 +134,19 @@ func LogHandler(logger *zap.Logger, pMetrics *pMetrics.PrometheusMetrics) fiber.
 		return nil
 	}
 }
+
+// statusClass maps a status code to its "2xx" style class
+func statusClass(status int) string {
+	switch {
+	case status >= 500:
+		return "5xx"
+	case status >= 400:
+		return "4xx"
+	case status >= 300:
+		return "3xx"
+	case status >= 200:
+		return "2xx"
+	default:
+		return "1xx"
+	}
+}
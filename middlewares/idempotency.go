@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/idempotency"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to
+// retry. The first response for a key is stored and replayed for retries
+// with the same method, path and body; reusing the key for a different
+// request is rejected. Server errors are not stored so they can be retried.
+func Idempotency(logger *zap.Logger, store *idempotency.Store) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(constants.HeaderIdempotencyKey)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		fingerprint := requestFingerprint(ctx)
+		record, err := store.Begin(key, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if record != nil {
+			ctx.Set(constants.HeaderIdempotentReplayed, "true")
+			ctx.Set(fiber.HeaderContentType, record.ContentType)
+			return ctx.Status(record.Status).Send(record.Body)
+		}
+
+		// The key is released unless a response is stored, also when the
+		// handler panics and Recover answers further up the chain
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(key)
+			}
+		}()
+
+		if err := ctx.Next(); err != nil {
+			// Render the error now so its response can be stored
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				return err
+			}
+		}
+
+		status := ctx.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			return nil
+		}
+
+		completed = true
+		err = store.Complete(key, idempotency.Record{
+			Status:      status,
+			ContentType: string(ctx.Response().Header.ContentType()),
+			Body:        append([]byte(nil), ctx.Response().Body()...),
+		})
+		if err != nil {
+			// The response was produced, only later replays are affected
+			utils.RequestLogger(ctx, logger).Error("Error storing idempotent response", zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// requestFingerprint identifies the request a key was first used with
+func requestFingerprint(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
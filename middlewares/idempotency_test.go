@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/idempotency"
+)
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	store, err := idempotency.NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	app := fiber.New()
+	// Panics are recovered outside the per-route middleware, as in routes.Setup
+	app.Use(recover.New())
+	app.Post("/apps", Idempotency(zap.NewNop(), store), func(c *fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return c.Status(fiber.StatusCreated).SendString("created")
+	})
+
+	post := func() int {
+		req := httptest.NewRequest(fiber.MethodPost, "/apps", strings.NewReader(`{"name":"a"}`))
+		req.Header.Set(constants.HeaderIdempotencyKey, "key")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := post(); status != fiber.StatusInternalServerError {
+		t.Fatalf("panicking request status = %d; want 500", status)
+	}
+	if status := post(); status != fiber.StatusCreated {
+		t.Fatalf("retry status = %d; want 201, the key must not stay in flight", status)
+	}
+	if status := post(); status != fiber.StatusCreated || calls != 2 {
+		t.Fatalf("replay status = %d after %d calls; want the stored 201 without calling the handler", status, calls)
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// RateLimit allows each client IP RATE_LIMIT_MAX requests per
+// RATE_LIMIT_WINDOW and does nothing when the maximum is 0. A reload that
+// changes the limits starts every client with a fresh window.
+func RateLimit(logger *zap.Logger, cfg config.AppConfig) fiber.Handler {
+	return reloadable(cfg, func(cfg config.AppConfig) fiber.Handler {
+		if cfg.RateLimitMax == 0 {
+			return next
+		}
+		return limiter.New(limiter.Config{
+			Max:        cfg.RateLimitMax,
+			Expiration: cfg.RateLimitWindow,
+			LimitReached: func(ctx *fiber.Ctx) error {
+				utils.RequestLogger(ctx, logger).Warn("rate limit reached", zap.String("ip", ctx.IP()))
+				return utils.NewAPIError(fiber.StatusTooManyRequests, constants.CodeRateLimited, constants.ErrRateLimited)
+			},
+		})
+	}, "RATE_LIMIT_")
+}
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// recoveredPanic is the error a recovered handler panic turns into. Recover
+// has already reported it, so the error handler does not report it again.
+type recoveredPanic struct {
+	value interface{}
+}
+
+func (p *recoveredPanic) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// Recover turns a panic in a later handler into a 500 response and reports
+// it to Sentry with the request, request ID and actor attached. It must run
+// after RequestID and Actor, and after LogHandler so the response is logged.
+func Recover(logger *zap.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			utils.RequestLogger(ctx, logger).Error("recovered from panic",
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			// Recovering inside the deferred function keeps the panicking
+			// frames in the stack trace Sentry records
+			requestHub(ctx).RecoverWithContext(ctx.UserContext(), r)
+			err = &recoveredPanic{value: r}
+		}()
+
+		return ctx.Next()
+	}
+}
+
+// captureError reports a 5xx error to Sentry unless Recover already did.
+// Writes refused during shutdown are expected and not reported.
+func captureError(ctx *fiber.Ctx, err error) {
+	var recovered *recoveredPanic
+	if errors.As(err, &recovered) || errors.Is(err, models.ErrShuttingDown) {
+		return
+	}
+	requestHub(ctx).CaptureException(err)
+}
+
+// requestHub returns a hub whose scope describes the request, so events
+// can be matched with logs through the request ID
+func requestHub(ctx *fiber.Ctx) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	scope := hub.Scope()
+
+	var req http.Request
+	if err := fasthttpadaptor.ConvertRequest(ctx.Context(), &req, true); err == nil {
+		scope.SetRequest(&req)
+	}
+	scope.SetTag(constants.LogFieldRequestID, utils.RequestID(ctx))
+	scope.SetUser(sentry.User{Username: utils.Actor(ctx), IPAddress: ctx.IP()})
+	return hub
+}
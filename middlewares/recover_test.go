@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/sentry"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// fakeSentry is an httptest Sentry endpoint keeping the body of every event
+type fakeSentry struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (f *fakeSentry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	data, _ := io.ReadAll(body)
+
+	f.mu.Lock()
+	f.events = append(f.events, string(data))
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// received returns the events whose body contains text. Events carry the
+// source around each stack frame, so text should be a quoted JSON field.
+func (f *fakeSentry) received(text string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matching []string
+	for _, event := range f.events {
+		if strings.Contains(event, text) {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestSentryReportsPanicsAndServerErrors(t *testing.T) {
+	endpoint := &fakeSentry{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	cfg := config.AppConfig{
+		SentryDSN:         strings.Replace(server.URL, "http://", "http://public@", 1) + "/1",
+		SentryEnvironment: "test",
+		SentrySampleRate:  1,
+	}
+	if err := sentry.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer sentrygo.CurrentHub().BindClient(nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(zap.NewNop(), cfg)})
+	app.Use(RequestID(zap.NewNop()))
+	app.Use(Actor(zap.NewNop()))
+	app.Use(Recover(zap.NewNop()))
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("handler exploded")
+	})
+	app.Get("/broken", func(c *fiber.Ctx) error {
+		return utils.WrapAPIError(errors.New("disk on fire"), fiber.StatusInternalServerError, constants.CodeStorageFailure, "storage failed")
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return utils.NewAPIError(fiber.StatusNotFound, constants.CodeAppNotFound, "no such thing")
+	})
+
+	requestIDs := map[string]string{}
+	for _, path := range []string{"/panic", "/broken", "/missing"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requestIDs[path] = resp.Header.Get(fiber.HeaderXRequestID)
+	}
+	if !sentrygo.Flush(5 * time.Second) {
+		t.Fatal("events were not sent to the fake Sentry endpoint")
+	}
+
+	panics := endpoint.received(`"message":"handler exploded"`)
+	if len(panics) != 1 {
+		t.Fatalf("got %d events for the panic; want exactly 1", len(panics))
+	}
+	if !strings.Contains(panics[0], `"request_id":"`+requestIDs["/panic"]+`"`) || !strings.Contains(panics[0], `"environment":"test"`) {
+		t.Errorf("panic event does not carry the request ID and environment: %s", panics[0])
+	}
+	if errs := endpoint.received(`"value":"storage failed: disk on fire"`); len(errs) != 1 || !strings.Contains(errs[0], `"request_id":"`+requestIDs["/broken"]+`"`) {
+		t.Errorf("got %d events for the 500 error; want 1 carrying its request ID", len(errs))
+	}
+	if missing := endpoint.received(`"value":"no such thing"`); len(missing) != 0 {
+		t.Errorf("a 404 was reported to Sentry: %s", missing[0])
+	}
+}
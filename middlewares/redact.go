@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const redactedValue = "[REDACTED]"
+
+// requestHeaders returns the request headers with sensitive values masked
+func (o httpLogOptions) requestHeaders(ctx *fiber.Ctx) map[string]string {
+	headers := map[string]string{}
+	ctx.Request().Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = o.headerValue(string(key), string(value))
+	})
+	return headers
+}
+
+// responseHeaders returns the response headers with sensitive values masked
+func (o httpLogOptions) responseHeaders(ctx *fiber.Ctx) map[string]string {
+	headers := map[string]string{}
+	ctx.Response().Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = o.headerValue(string(key), string(value))
+	})
+	return headers
+}
+
+func (o httpLogOptions) headerValue(key, value string) string {
+	if o.redactHeaders[strings.ToLower(key)] {
+		return redactedValue
+	}
+	return value
+}
+
+// body masks configured fields of a JSON body and truncates it to the
+// configured size. Non JSON bodies are only truncated.
+func (o httpLogOptions) body(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	if len(o.redactFields) > 0 {
+		var payload interface{}
+		if err := json.Unmarshal(raw, &payload); err == nil {
+			if masked, err := json.Marshal(o.redact(payload)); err == nil {
+				raw = masked
+			}
+		}
+	}
+
+	if o.maxBodyBytes > 0 && len(raw) > o.maxBodyBytes {
+		return fmt.Sprintf("%s...(truncated %d bytes)", raw[:o.maxBodyBytes], len(raw)-o.maxBodyBytes)
+	}
+	return string(raw)
+}
+
+// redact walks a decoded JSON value and masks every configured field
+func (o httpLogOptions) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if o.redactFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = o.redact(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = o.redact(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// lowerSet turns a list into a case insensitive lookup set
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			set[strings.ToLower(value)] = true
+		}
+	}
+	return set
+}
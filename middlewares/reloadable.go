@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+// reloadable runs the handler build returns for the current config, and
+// builds it again when a config reload changes a setting starting with
+// one of prefixes
+func reloadable(cfg config.AppConfig, build func(config.AppConfig) fiber.Handler, prefixes ...string) fiber.Handler {
+	var current atomic.Value
+	current.Store(build(cfg))
+	config.OnReload(func(_ context.Context, cfg config.AppConfig, changes []config.Change) {
+		if config.Changed(changes, prefixes...) {
+			current.Store(build(cfg))
+		}
+	})
+
+	return func(ctx *fiber.Ctx) error {
+		return current.Load().(fiber.Handler)(ctx)
+	}
+}
+
+// next is the handler of a disabled middleware
+func next(ctx *fiber.Ctx) error {
+	return ctx.Next()
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Incoming request IDs are only trusted if they look like an opaque token,
+// so a client cannot inject arbitrary text into our logs and headers
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates a new one,
+// echoes it on the response and attaches a child logger carrying it
+// to the request so controllers and models log with the same ID.
+func RequestID(log *zap.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(fiber.HeaderXRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(fiber.HeaderXRequestID, requestID)
+		ctx.Locals(constants.LocalRequestID, requestID)
+
+		reqLogger := log.With(zap.String(constants.LogFieldRequestID, requestID))
+		userCtx := utils.ContextWithRequestID(ctx.UserContext(), requestID)
+		ctx.SetUserContext(logger.WithContext(userCtx, reqLogger))
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Tracing starts a server span for every request, continuing any trace
+// passed in the W3C traceparent header, and returns the span context to
+// the caller through the same header. The span is stored in the user
+// context so models create child spans, and the trace ID is added to the
+// request logger.
+func Tracing(log *zap.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		propagator := otel.GetTextMapPropagator()
+
+		carrier := propagation.HeaderCarrier(http.Header{})
+		ctx.Request().Header.VisitAll(func(key, value []byte) {
+			carrier.Set(string(key), string(value))
+		})
+		parent := propagator.Extract(ctx.UserContext(), carrier)
+
+		spanCtx, span := tracing.Start(parent, ctx.Method()+" "+ctx.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", ctx.Method()),
+				attribute.String("http.target", string(ctx.Request().RequestURI())),
+				attribute.String("http.scheme", ctx.Protocol()),
+				attribute.String("net.host.name", ctx.Hostname()),
+				attribute.String("request_id", utils.RequestID(ctx)),
+			),
+		)
+		defer span.End()
+
+		if traceID := tracing.TraceID(spanCtx); traceID != "" {
+			reqLogger := logger.FromContext(spanCtx, log).With(zap.String("trace_id", traceID))
+			spanCtx = logger.WithContext(spanCtx, reqLogger)
+		}
+		ctx.SetUserContext(spanCtx)
+
+		err := ctx.Next()
+
+		// The matched route is only known once the handler chain ran
+		span.SetName(ctx.Method() + " " + ctx.Route().Path)
+		span.SetAttributes(attribute.String("http.route", ctx.Route().Path))
+
+		status := ctx.Response().StatusCode()
+		if err != nil {
+			span.RecordError(err)
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		responseCarrier := propagation.HeaderCarrier(http.Header{})
+		propagator.Inject(spanCtx, responseCarrier)
+		for _, key := range responseCarrier.Keys() {
+			ctx.Set(key, responseCarrier.Get(key))
+		}
+
+		return err
+	}
+}
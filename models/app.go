@@ -1,44 +1,50 @@
 package models
 
 import (
-	"bytes"
-	"encoding/csv"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
-	"github.com/jszwec/csvutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-// Global Cache Variables
+// Global Cache Variables. appFile is the apps CSV file as last read or
+// written, another process such as the import command may change it.
 var (
 	appCache []App
+	appFile  os.FileInfo
 	appMutex sync.RWMutex
-	appOnce  sync.Once
 )
 
 // App represents the structure of each row in CSV
 type App struct {
-	Name          string  `csv:"App" validate:"required"`
-	Category      string  `csv:"Category" validate:"required"`
-	Rating        float64 `csv:"Rating" validate:"gte=0,lte=5"`
-	Reviews       int     `csv:"Reviews" validate:"gte=0"`
-	Size          string  `csv:"Size" validate:"required"`
-	Installs      string  `csv:"Installs" validate:"required"`
-	Type          string  `csv:"Type" validate:"required"`
-	Price         string  `csv:"Price" validate:"required"`
-	ContentRating string  `csv:"Content Rating" validate:"required"`
-	Genres        string  `csv:"Genres" validate:"required"`
-	LastUpdated   string  `csv:"Last Updated" validate:"required"`
-	CurrentVer    string  `csv:"Current Ver" validate:"required"`
-	AndroidVer    string  `csv:"Android Ver" validate:"required"`
+	Name          string   `csv:"App" validate:"required"`
+	Category      string   `csv:"Category" validate:"required"`
+	Rating        float64  `csv:"Rating" validate:"gte=0,lte=5"`
+	Reviews       int      `csv:"Reviews" validate:"gte=0"`
+	Size          string   `csv:"Size" validate:"required"`
+	Installs      string   `csv:"Installs" validate:"required,installs"`
+	Type          string   `csv:"Type" validate:"required"`
+	Price         string   `csv:"Price" validate:"required,price"`
+	ContentRating string   `csv:"Content Rating" validate:"required"`
+	Genres        string   `csv:"Genres" validate:"required"`
+	LastUpdated   string   `csv:"Last Updated" validate:"required"`
+	CurrentVer    string   `csv:"Current Ver" validate:"required"`
+	AndroidVer    string   `csv:"Android Ver" validate:"required,android_version"`
+	Metadata      Metadata `csv:"-" json:"metadata,omitempty"`
 }
 
 // AppModel contains the logger and config
@@ -55,91 +61,169 @@ func NewAppModel(logger *zap.Logger, config config.AppConfig) *AppModel {
 	}
 }
 
-// loadCache: Loads app data into cache
-func (am *AppModel) loadCache() error {
-	apps, err := am.ParseApps()
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (am *AppModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, am.logger)
+}
+
+// loadCache: Loads app data into cache. The caller must hold the
+// write lock of appMutex.
+func (am *AppModel) loadCache(ctx context.Context) error {
+	// Changes made while parsing are seen by the next read
+	info := statFile(am.config.CSVFilePath)
+	apps, err := am.ParseApps(ctx)
 	if err != nil {
 		return err
 	}
 	appCache = apps
+	appFile = info
 	return nil
 }
 
 // GetAppsFromCache: Returns data from cache or loads it if expired
-func (am *AppModel) GetAppsFromCache() ([]App, error) {
-	// First-time cache load
-	appOnce.Do(func() {
-		_ = am.loadCache()
-	})
+func (am *AppModel) GetAppsFromCache(ctx context.Context) ([]App, error) {
 	appMutex.RLock()
-	defer appMutex.RUnlock()
-	if len(appCache) == 0 {
-		err := am.loadCache()
-		if err != nil {
+	if !am.cacheStale() {
+		defer appMutex.RUnlock()
+		return appCache, nil
+	}
+	appMutex.RUnlock()
+
+	// Loads write the cache, another request may have loaded it meanwhile
+	appMutex.Lock()
+	defer appMutex.Unlock()
+	return am.cachedApps(ctx)
+}
+
+// statFile: Returns the info of the file at path, or nil when it cannot
+// be stat so the file is read again
+func statFile(path string) os.FileInfo {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// cacheStale: Reports whether the cache was never loaded or the CSV file
+// changed since it was last read or written. The caller must hold appMutex.
+func (am *AppModel) cacheStale() bool {
+	_, changed, err := utils.FileChanged(am.config.CSVFilePath, appFile)
+	return appFile == nil || changed || err != nil
+}
+
+// cachedApps: Returns the cached apps, loading them when the cache was
+// never loaded or the CSV file changed. The caller must hold the write
+// lock of appMutex.
+func (am *AppModel) cachedApps(ctx context.Context) ([]App, error) {
+	if am.cacheStale() {
+		if err := am.loadCache(ctx); err != nil {
 			return nil, err
 		}
 	}
 	return appCache, nil
 }
 
-// ParseApps: Reads and parses apps from CSV using csvutil
-func (am *AppModel) ParseApps() ([]App, error) {
+// Reload: Reads the apps CSV file again whether or not the cache is
+// filled and reports the reload to the mutation observers. Returns the
+// number of rows loaded.
+func (am *AppModel) Reload(ctx context.Context) (int, error) {
+	appMutex.Lock()
+	defer appMutex.Unlock()
+
+	if err := am.loadCache(ctx); err != nil {
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionReload,
+		Entity: EntityDataset,
+		Key:    EntityApp,
+		After:  map[string]int{"rows": len(appCache)},
+	})
+	return len(appCache), nil
+}
+
+// ParseApps: Streams and parses apps from CSV using csvutil, decoding and
+// normalizing rows in parallel. Rows that do not decode are quarantined
+// and the valid ones are returned.
+func (am *AppModel) ParseApps(ctx context.Context) (apps []App, err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.ParseApps",
+		trace.WithAttributes(attribute.String("csv.path", am.config.CSVFilePath)))
+	defer func() { tracing.End(span, err) }()
+
 	if am.config.CSVFilePath == "" {
-		return nil, errors.New("CSV file path is not configured")
+		return nil, ErrFilePathNotConfigured
 	}
 
-	var apps []App
-	records, err := utils.ReadCSV(am.config.CSVFilePath)
+	file, err := os.Open(am.config.CSVFilePath)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("failed to open CSV file")
 	}
-	if err := csvutil.Unmarshal(records, &apps); err != nil {
+	defer file.Close()
+
+	// Rows that do not decode are quarantined instead of failing the load
+	_, decodeSpan := tracing.Start(ctx, "csv.unmarshal")
+	apps, skipped, err := parseLenient(file, appsCSV(am.config), am.config.CSVParseWorkers, prepareApp)
+	decodeSpan.SetAttributes(
+		attribute.Int("csv.rows", len(apps)),
+		attribute.Int("csv.quarantined", len(skipped)),
+	)
+	tracing.End(decodeSpan, err)
+	if err != nil {
 		return nil, err
 	}
-
-	// Post-processing: Remove "$" from Price and format Installs
-	for i := range apps {
-		cleanedPrice := cleanPriceStr(apps[i].Price)
-		priceFloat, err := strconv.ParseFloat(cleanedPrice, 64)
-		if err != nil {
-			priceFloat = 0 // Default to 0 if conversion fails
-		}
-		apps[i].Price = strconv.FormatFloat(priceFloat, 'f', 2, 64)
-		apps[i].Installs = cleanInstalls(apps[i].Installs)
+	// Without its lines a row that is not valid CSV blocks rewrites
+	if err := readRawLines(am.config.CSVFilePath, appsCSV(am.config), skipped); err != nil {
+		am.log(ctx).Warn("Error reading the lines of quarantined rows", zap.Error(err))
 	}
+	NewQuarantineModel(am.logger, am.config).quarantine(ctx, EntityApp, am.config.CSVFilePath, skipped)
 	return apps, nil
 }
 
-// ListAllApps: Returns apps with pagination and filters
-func (am *AppModel) ListAllApps(limit int, page int, priceFilter string) ([]string, error) {
-	apps, err := am.GetAppsFromCache()
+// StreamApps: Streams the apps CSV file r in the apps dialect, decoding
+// and normalizing rows on workers goroutines the way ParseApps does, and
+// hands each row to fn in file order. Nothing is kept or quarantined, it
+// returns the number of rows that did not decode.
+func StreamApps(r io.Reader, cfg config.AppConfig, workers int, fn func(App)) (skipped int, err error) {
+	err = streamCSV(r, appsCSV(cfg), workers, prepareApp, func(_ int, app App) {
+		fn(app)
+	}, func(int, []string, error) {
+		skipped++
+	})
+	return skipped, err
+}
+
+// prepareApp: Removes "$" from Price and formats Installs of a decoded
+// app, every app is kept
+func prepareApp(app *App) bool {
+	*app = normalizeApp(*app)
+	return true
+}
+
+// ListAllApps: Returns apps with pagination and filters.
+// A non-zero asOf answers from the dataset as it was at that time.
+func (am *AppModel) ListAllApps(ctx context.Context, limit int, page int, priceFilter string, asOf time.Time) (appNames []string, err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.ListAllApps", trace.WithAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("page", page),
+		attribute.String("filter.price", priceFilter),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("result.count", len(appNames)))
+		tracing.End(span, err)
+	}()
+
+	if !asOf.IsZero() {
+		span.SetAttributes(attribute.String("as_of", asOf.Format(time.RFC3339)))
+	}
+	_, filterSpan := tracing.Start(ctx, "apps.filter")
+	filteredApps, err := am.FilterApps(ctx, priceFilter, asOf)
+	filterSpan.SetAttributes(attribute.Int("filter.matched", len(filteredApps)))
+	tracing.End(filterSpan, err)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply filters
-	var filteredApps []App
-	for _, app := range apps {
-		// Apply price filter if provided
-		if priceFilter != "" {
-			price, err := strconv.ParseFloat(priceFilter, 64)
-			if err != nil {
-				return nil, fmt.Errorf("Invalid price value")
-			}
-
-			// Convert app.Price (string) to float64
-			appPrice, err := strconv.ParseFloat(app.Price, 64)
-			if err != nil {
-				continue // Skip invalid price values
-			}
-
-			if appPrice != price {
-				continue
-			}
-		}
-		filteredApps = append(filteredApps, app)
-	}
-
 	totalApps := len(filteredApps)
 	offset := (page - 1) * limit
 	if offset >= totalApps {
@@ -148,7 +232,6 @@ func (am *AppModel) ListAllApps(limit int, page int, priceFilter string) ([]stri
 
 	// Apply pagination
 	end := min(offset+limit, totalApps)
-	var appNames []string
 	for _, app := range filteredApps[offset:end] {
 		appNames = append(appNames, app.Name)
 	}
@@ -156,97 +239,263 @@ func (am *AppModel) ListAllApps(limit int, page int, priceFilter string) ([]stri
 	return appNames, nil
 }
 
-func (am *AppModel) AddAppData(app App) error {
+// FilterApps: Returns the apps whose price equals priceFilter, or every
+// app when it is empty. A non-zero asOf answers from the dataset as it
+// was at that time.
+func (am *AppModel) FilterApps(ctx context.Context, priceFilter string, asOf time.Time) ([]App, error) {
+	apps, err := am.GetAppsFromCache(ctx)
+	if err != nil {
+		am.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return nil, err
+	}
+	if !asOf.IsZero() {
+		apps, err = NewRevisionModel(am.logger, am.config).appsAsOf(apps, asOf)
+		if err != nil {
+			am.log(ctx).Error("Error rebuilding apps from revisions", zap.Error(err))
+			return nil, err
+		}
+	}
+	if priceFilter == "" {
+		return apps, nil
+	}
+
+	price, err := strconv.ParseFloat(priceFilter, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPrice, priceFilter)
+	}
+	var filtered []App
+	for _, app := range apps {
+		// Skip invalid price values
+		if appPrice, err := strconv.ParseFloat(app.Price, 64); err == nil && appPrice == price {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered, nil
+}
+
+// AddAppData: Appends an app to the CSV file and the cache.
+// Duplicates of existing apps are handled according to the duplicate policy.
+func (am *AppModel) AddAppData(ctx context.Context, app App) (result InsertResult, err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.AddAppData",
+		trace.WithAttributes(attribute.String("app.name", app.Name)))
+	defer func() {
+		span.SetAttributes(attribute.String("insert.result", string(result)))
+		tracing.End(span, err)
+	}()
+
+	done, err := beginWrite()
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
 	appMutex.Lock()
 	defer appMutex.Unlock()
 
-	file, err := os.OpenFile(am.config.CSVFilePath, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	apps, err := am.cachedApps(ctx)
 	if err != nil {
-		return err
+		am.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return "", err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	record := []string{
-		app.Name,
-		app.Category,
-		strconv.FormatFloat(app.Rating, 'f', 6, 64),
-		strconv.Itoa(app.Reviews),
-		app.Size,
-		app.Installs,
-		app.Type,
-		app.Price,
-		app.ContentRating,
-		app.Genres,
-		app.LastUpdated,
-		app.CurrentVer,
-		app.AndroidVer,
-	}
-
-	if err := writer.Write(record); err != nil {
-		return err
+	merge, err := am.applyDuplicatePolicy(apps, app)
+	if err != nil {
+		return "", err
+	}
+	if merge != nil {
+		if err := am.writeApps(ctx, merge.apps); err != nil {
+			return "", err
+		}
+		notifyMutation(ctx, Mutation{
+			Action: ActionMerge,
+			Entity: EntityApp,
+			Key:    app.Name,
+			Before: merge.replaced,
+			After:  merge.kept,
+		})
+		return InsertMerged, nil
 	}
 
-	// Append to the in-memory cache
-	appCache = append(appCache, app)
+	// Appends follow the file header, a file lacking a column the app
+	// fills is rewritten whole with the column added
+	opts := appsCSV(am.config)
+	schema, err := readCSVSchema[App](am.config.CSVFilePath, opts)
+	if err != nil {
+		am.log(ctx).Error(constants.ErrReadingCSVRecords, zap.Error(err))
+		return "", err
+	}
+	if extended := extendSchema(schema, []App{app}); len(extended.header) > len(schema.header) {
+		all := append(append(make([]App, 0, len(apps)+1), apps...), normalizeApp(app))
+		if err := am.writeApps(ctx, all); err != nil {
+			return "", err
+		}
+	} else {
+		if err := appendCSV(am.config.CSVFilePath, opts.dialect, schema, []App{app}); err != nil {
+			am.log(ctx).Error(constants.ErrWritingCSVRecords, zap.Error(err))
+			return "", err
+		}
+		// Append to the in-memory cache, cleaned the way ParseApps cleans rows
+		appCache = append(appCache, normalizeApp(app))
+		appFile = statFile(am.config.CSVFilePath)
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionCreate,
+		Entity: EntityApp,
+		Key:    app.Name,
+		After:  normalizeApp(app),
+	})
 
-	return nil
+	return InsertCreated, nil
 }
-func (am *AppModel) DeleteApp(appName string) error {
+
+// DeleteApp: Moves every row with the given app name to the trash and rewrites the CSV
+func (am *AppModel) DeleteApp(ctx context.Context, appName string) (err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.DeleteApp",
+		trace.WithAttributes(attribute.String("app.name", appName)))
+	defer func() { tracing.End(span, err) }()
+
+	done, err := beginWrite()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	appMutex.Lock()
 	defer appMutex.Unlock()
 
-	// 1. Read all apps from CSV
-	apps, err := am.ParseApps()
+	// 1. Read all apps from the cache, loading the CSV when it changed
+	apps, err := am.cachedApps(ctx)
 	if err != nil {
+		am.log(ctx).Error(constants.ErrParsingCSV, zap.Error(err))
 		return errors.New(constants.ErrParsingCSV) // Use constant here
 	}
 
 	// 2. Filter out the app to be deleted
-	var updatedApps []App
-	found := false
+	var updatedApps, deletedApps []App
 	for _, app := range apps {
 		if app.Name != appName {
 			updatedApps = append(updatedApps, app)
 		} else {
-			found = true
+			deletedApps = append(deletedApps, app)
 		}
 	}
 
-	if !found {
-		return errors.New(constants.AppNotFoundErrorMessage) // Use constant here
+	if len(deletedApps) == 0 {
+		return ErrAppNotFound
 	}
 
-	// 3. Rewrite the CSV file
-	file, err := os.Create(am.config.CSVFilePath) // Create overwrites the file
+	// 3. Keep the deleted rows in the trash so they can be restored
+	trashID, err := moveToTrash(ctx, am.config.TrashFilePath, TrashEntry{
+		Kind: TrashKindApp,
+		Name: appName,
+		Apps: deletedApps,
+	})
 	if err != nil {
-		return errors.New(constants.ErrCreatingCSVFile) // Use constant here
+		am.log(ctx).Error("Error moving app to trash", zap.Error(err))
+		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	// 4. Rewrite the CSV file and update the in-memory cache
+	if err := am.writeApps(ctx, updatedApps); err != nil {
+		if dropErr := dropFromTrash(am.config.TrashFilePath, trashID); dropErr != nil {
+			am.log(ctx).Error("Error removing trash entry of failed delete", zap.Error(dropErr))
+		}
+		return err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionDelete,
+		Entity: EntityApp,
+		Key:    appName,
+		Before: deletedApps,
+	})
+	return nil
+}
 
-	csvBytes, err := csvutil.Marshal(updatedApps)
+// RestoreApp: Puts back the rows removed by the most recent delete of appName
+func (am *AppModel) RestoreApp(ctx context.Context, appName string) (restored int, err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.RestoreApp",
+		trace.WithAttributes(attribute.String("app.name", appName)))
+	defer func() { tracing.End(span, err) }()
+
+	done, err := beginWrite()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	appMutex.Lock()
+	defer appMutex.Unlock()
+
+	apps, err := am.cachedApps(ctx)
 	if err != nil {
-		return errors.New(constants.ErrMarshallingCSVData) // Use constant here
+		am.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return 0, err
 	}
 
-	r := csv.NewReader(bytes.NewReader(csvBytes))
-	records, err := r.ReadAll()
+	entry, err := takeFromTrash(am.config.TrashFilePath, TrashKindApp, appName)
+	if err != nil {
+		return 0, err
+	}
+
+	restoredApps := append(append(make([]App, 0, len(apps)+len(entry.Apps)), apps...), entry.Apps...)
+	if err := am.writeApps(ctx, restoredApps); err != nil {
+		if returnErr := returnToTrash(am.config.TrashFilePath, entry); returnErr != nil {
+			am.log(ctx).Error("Error returning entry to trash", zap.Error(returnErr))
+		}
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionRestore,
+		Entity: EntityApp,
+		Key:    appName,
+		After:  entry.Apps,
+	})
+	return len(entry.Apps), nil
+}
+
+// writeApps: Atomically rewrites the CSV file with apps and replaces the cache.
+// Columns keep the names and order of the file header; fields and metadata
+// columns it lacks are appended. Quarantined rows keep their place among
+// the cached rows. The caller must hold appMutex.
+func (am *AppModel) writeApps(ctx context.Context, apps []App) error {
+	opts := appsCSV(am.config)
+	schema, err := readCSVSchema[App](am.config.CSVFilePath, opts)
 	if err != nil {
+		am.log(ctx).Error(constants.ErrReadingCSVRecords, zap.Error(err))
 		return errors.New(constants.ErrReadingCSVRecords) // Use constant here
 	}
 
-	err = writer.WriteAll(records)
+	// Quarantined rows are written back behind the rows that preceded them
+	quarantine := NewQuarantineModel(am.logger, am.config)
+	held, err := quarantine.heldRows(EntityApp)
 	if err != nil {
-		return errors.New(constants.ErrWritingCSVRecords) // Use constant here
+		am.log(ctx).Error("Error loading quarantine", zap.Error(err))
+		return errors.New(constants.ErrReadingCSVRecords) // Use constant here
+	}
+	placeHeld(held, appCache, apps, duplicateKey)
+	from := make([]int, len(held))
+	for i := range held {
+		from[i] = held[i].Line
+	}
+
+	// Rows are encoded straight into the temporary file, which replaces the
+	// CSV file in one rename so a crash never leaves it truncated
+	err = utils.WriteFileAtomicFunc(am.config.CSVFilePath, 0o644, func(w io.Writer) error {
+		return writeCSV(w, opts.dialect, extendSchema(schema, apps), apps, held)
+	})
+	if err != nil {
+		am.log(ctx).Error(constants.ErrCreatingCSVFile, zap.Error(err))
+		if errors.Is(err, ErrQuarantineUnrecoverable) {
+			return err
+		}
+		return errors.New(constants.ErrCreatingCSVFile) // Use constant here
 	}
 
-	// 4. Update the in-memory cache
-	appCache = updatedApps
+	quarantine.moveHeld(ctx, EntityApp, from, held)
+
+	// Update the in-memory cache
+	appCache = apps
+	appFile = statFile(am.config.CSVFilePath)
 
 	return nil
 }
@@ -268,6 +517,15 @@ func cleanPriceStr(priceStr string) string {
 	return priceStr
 }
 
+// formatPrice: Formats a price as a plain decimal with two places
+func formatPrice(priceStr string) string {
+	priceFloat, err := strconv.ParseFloat(cleanPriceStr(priceStr), 64)
+	if err != nil {
+		priceFloat = 0 // Default to 0 if conversion fails
+	}
+	return strconv.FormatFloat(priceFloat, 'f', 2, 64)
+}
+
 // cleanInstalls: Removes commas from installs field
 func cleanInstalls(installs string) string {
 	return strings.ReplaceAll(installs, ",", "")
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestOnlyExplicitReloadsAreReported(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	var reloads atomic.Int32
+	OnMutation(func(_ context.Context, mutation Mutation) {
+		if mutation.Action == ActionReload && mutation.Key == EntityApp {
+			reloads.Add(1)
+		}
+	})
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+	}
+	// An empty dataset is loaded again by every read
+	if err := os.WriteFile(cfg.CSVFilePath, []byte(appsHeader), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := am.GetAppsFromCache(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := reloads.Load(); n != 0 {
+		t.Fatalf("lazy cache loads reported %d reloads; want none", n)
+	}
+
+	if rows, err := am.Reload(ctx); err != nil || rows != 0 {
+		t.Fatalf("Reload = %d, %v; want 0 rows", rows, err)
+	}
+	if n := reloads.Load(); n != 1 {
+		t.Errorf("Reload reported %d reloads; want 1", n)
+	}
+}
+
+func TestRewritesSeeRowsAppendedByOtherProcesses(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+		TrashFilePath:      filepath.Join(dir, "trash.json"),
+	}
+	if err := os.WriteFile(cfg.CSVFilePath, appsFile(3), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+	if apps, err := am.GetAppsFromCache(ctx); err != nil || len(apps) != 2 {
+		t.Fatalf("GetAppsFromCache = %d apps, %v; want 2", len(apps), err)
+	}
+
+	// The import command runs in its own process and appends to the file
+	file, err := os.OpenFile(cfg.CSVFilePath, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteString("Imported,TOOLS,4.0,1,1M,\"1,000+\",Free,0,Everyone,Tools,\"May 2, 2018\",1.0,4.1 and up\n")
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := am.DeleteApp(ctx, "App 1"); err != nil {
+		t.Fatal(err)
+	}
+	apps, err := am.ParseApps(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	if len(names) != 2 || names[0] != "App 2" || names[1] != "Imported" {
+		t.Errorf("apps after delete = %q; want App 2 and the imported row", names)
+	}
+}
@@ -0,0 +1,393 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Duplicate policies applied when a row is inserted
+const (
+	DuplicatePolicyReject = "reject"
+	DuplicatePolicyMerge  = "merge"
+	DuplicatePolicyAllow  = "allow"
+)
+
+// Kinds of duplicates
+const (
+	DuplicateExact = "exact"
+	DuplicateNear  = "near"
+)
+
+// InsertResult reports what an insert did with the submitted row
+type InsertResult string
+
+const (
+	InsertCreated InsertResult = "created"
+	InsertMerged  InsertResult = "merged"
+)
+
+// lastUpdatedLayout is the date format of the Play Store "Last Updated" column
+const lastUpdatedLayout = "January 2, 2006"
+
+// DuplicateRow is one row of a duplicate group with its position in the CSV
+type DuplicateRow struct {
+	Row int `json:"row"`
+	App App `json:"app"`
+}
+
+// DuplicateGroup is a set of apps sharing a normalized name and category
+type DuplicateGroup struct {
+	Key      string         `json:"key"`
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name"`
+	Category string         `json:"category"`
+	Rows     []DuplicateRow `json:"rows"`
+}
+
+// ReviewDuplicateGroup is a set of identical reviews
+type ReviewDuplicateGroup struct {
+	Key    string `json:"key"`
+	Review Review `json:"review"`
+	Rows   []int  `json:"rows"`
+}
+
+// normalizeText: Lowercases s, drops punctuation and collapses whitespace
+func normalizeText(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '_' || r == '-':
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// duplicateKey: Returns the key near-duplicate apps share
+func duplicateKey(app App) string {
+	return normalizeText(app.Name) + "|" + normalizeText(app.Category)
+}
+
+// reviewKey: Returns the key identical reviews share
+func reviewKey(review Review) string {
+	return normalizeText(review.App) + "|" + normalizeText(review.TranslatedReview) + "|" + normalizeText(review.Sentiment)
+}
+
+// normalizeApp: Applies the same cleaning ParseApps does so submitted apps
+// compare equal to cached rows
+func normalizeApp(app App) App {
+	app.Price = formatPrice(app.Price)
+	app.Installs = cleanInstalls(app.Installs)
+	return app
+}
+
+// lastUpdated: Parses the "Last Updated" column, unparseable dates sort first
+func lastUpdated(app App) time.Time {
+	t, err := time.Parse(lastUpdatedLayout, strings.TrimSpace(app.LastUpdated))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// mostRecent: Returns the index of the most recently updated app.
+// Ties go to the later row, which was appended last.
+func mostRecent(apps []App) int {
+	best := 0
+	for i := 1; i < len(apps); i++ {
+		if !lastUpdated(apps[i]).Before(lastUpdated(apps[best])) {
+			best = i
+		}
+	}
+	return best
+}
+
+// groupDuplicates: Groups apps by duplicate key, keeping groups of two or more
+func groupDuplicates(apps []App) []DuplicateGroup {
+	byKey := map[string][]DuplicateRow{}
+	var keys []string
+	for i, app := range apps {
+		key := duplicateKey(app)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], DuplicateRow{Row: i, App: app})
+	}
+
+	groups := []DuplicateGroup{}
+	for _, key := range keys {
+		rows := byKey[key]
+		if len(rows) < 2 {
+			continue
+		}
+		kind := DuplicateExact
+		for _, row := range rows[1:] {
+			if row.App != rows[0].App {
+				kind = DuplicateNear
+				break
+			}
+		}
+		groups = append(groups, DuplicateGroup{
+			Key:      key,
+			Kind:     kind,
+			Name:     rows[0].App.Name,
+			Category: rows[0].App.Category,
+			Rows:     rows,
+		})
+	}
+	return groups
+}
+
+// FindDuplicates: Reports exact and near duplicate apps in the dataset
+func (am *AppModel) FindDuplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	apps, err := am.GetAppsFromCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupDuplicates(apps), nil
+}
+
+// MergeDuplicates: Collapses every duplicate group, or only the group with
+// the given key, into its most recently updated row.
+// Returns the number of rows removed.
+func (am *AppModel) MergeDuplicates(ctx context.Context, key string) (int, error) {
+	done, err := beginWrite()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	appMutex.Lock()
+	defer appMutex.Unlock()
+
+	apps, err := am.cachedApps(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	groups := groupDuplicates(apps)
+	drop := map[int]bool{}
+	replace := map[int]App{}
+	var mutations []Mutation
+	for _, group := range groups {
+		if key != "" && group.Key != key {
+			continue
+		}
+		candidates := make([]App, len(group.Rows))
+		for i, row := range group.Rows {
+			candidates[i] = row.App
+		}
+		// The group keeps the position of its first row
+		winner := candidates[mostRecent(candidates)]
+		replace[group.Rows[0].Row] = winner
+		for _, row := range group.Rows[1:] {
+			drop[row.Row] = true
+		}
+		mutations = append(mutations, Mutation{
+			Action: ActionMerge,
+			Entity: EntityApp,
+			Key:    group.Name,
+			Before: candidates,
+			After:  winner,
+		})
+	}
+
+	if key != "" && len(replace) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrDuplicateGroupNotFound, key)
+	}
+	if len(drop) == 0 {
+		return 0, nil
+	}
+
+	merged := make([]App, 0, len(apps)-len(drop))
+	for i, app := range apps {
+		if drop[i] {
+			continue
+		}
+		if winner, ok := replace[i]; ok {
+			app = winner
+		}
+		merged = append(merged, app)
+	}
+
+	if err := am.writeApps(ctx, merged); err != nil {
+		return 0, err
+	}
+	for _, mutation := range mutations {
+		notifyMutation(ctx, mutation)
+	}
+	return len(drop), nil
+}
+
+// appMerge is the outcome of merging an inserted app into existing rows
+type appMerge struct {
+	apps     []App // the rewritten dataset
+	replaced []App // the existing rows that were merged
+	kept     App   // the row the merge kept
+}
+
+// applyDuplicatePolicy: Decides how app is inserted into apps according to
+// the configured policy. It returns the merge when the app was merged into
+// existing rows, or nil when the app should be appended.
+// The caller must hold appMutex.
+func (am *AppModel) applyDuplicatePolicy(apps []App, app App) (*appMerge, error) {
+	policy := strings.ToLower(am.config.DuplicatePolicy)
+	if policy == DuplicatePolicyAllow {
+		return nil, nil
+	}
+
+	key := duplicateKey(app)
+	normalized := normalizeApp(app)
+	var matches []int
+	for i, existing := range apps {
+		if duplicateKey(existing) != key {
+			continue
+		}
+		if policy != DuplicatePolicyMerge {
+			kind := DuplicateNear
+			if existing == normalized {
+				kind = DuplicateExact
+			}
+			return nil, fmt.Errorf("%w: %s duplicate of row %d", ErrDuplicateApp, kind, i)
+		}
+		matches = append(matches, i)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	// Merge: keep the most recently updated of the existing rows and the new one
+	replaced := make([]App, 0, len(matches))
+	for _, i := range matches {
+		replaced = append(replaced, apps[i])
+	}
+	candidates := append(append([]App{}, replaced...), normalized)
+	winner := candidates[mostRecent(candidates)]
+
+	// The merged row takes the position of the first match
+	drop := map[int]bool{}
+	for _, i := range matches[1:] {
+		drop[i] = true
+	}
+	merged := make([]App, 0, len(apps)-len(drop))
+	for i, existing := range apps {
+		switch {
+		case i == matches[0]:
+			merged = append(merged, winner)
+		case !drop[i]:
+			merged = append(merged, existing)
+		}
+	}
+	return &appMerge{apps: merged, replaced: replaced, kept: winner}, nil
+}
+
+// applyDuplicatePolicy: Reports whether review is identical to a stored
+// review, or rejects it when the policy says so. Reviews carry no update
+// date, so merging keeps the stored row.
+// The caller must hold reviewMutex.
+func (rm *ReviewModel) applyDuplicatePolicy(reviews []Review, review Review) (bool, error) {
+	policy := strings.ToLower(rm.config.DuplicatePolicy)
+	if policy == DuplicatePolicyAllow {
+		return false, nil
+	}
+
+	key := reviewKey(review)
+	for i, existing := range reviews {
+		if reviewKey(existing) != key {
+			continue
+		}
+		if policy != DuplicatePolicyMerge {
+			return false, fmt.Errorf("%w: duplicate of row %d", ErrDuplicateReview, i)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// FindDuplicateReviews: Reports reviews that appear more than once
+func (rm *ReviewModel) FindDuplicateReviews(ctx context.Context) ([]ReviewDuplicateGroup, error) {
+	reviews, err := rm.ListReviewsFromCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupDuplicateReviews(reviews), nil
+}
+
+// MergeDuplicateReviews: Keeps the first of every set of identical reviews.
+// Returns the number of rows removed.
+func (rm *ReviewModel) MergeDuplicateReviews(ctx context.Context) (int, error) {
+	done, err := beginWrite()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	reviews, err := rm.cachedReviews(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := map[string]bool{}
+	deduped := make([]Review, 0, len(reviews))
+	var dropped []Review
+	for _, review := range reviews {
+		key := reviewKey(review)
+		if seen[key] {
+			dropped = append(dropped, review)
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, review)
+	}
+
+	removed := len(reviews) - len(deduped)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := rm.writeReviews(ctx, deduped); err != nil {
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionMerge,
+		Entity: EntityReview,
+		Before: dropped,
+	})
+	return removed, nil
+}
+
+// groupDuplicateReviews: Groups identical reviews, keeping groups of two or more
+func groupDuplicateReviews(reviews []Review) []ReviewDuplicateGroup {
+	byKey := map[string]*ReviewDuplicateGroup{}
+	var keys []string
+	for i, review := range reviews {
+		key := reviewKey(review)
+		group, ok := byKey[key]
+		if !ok {
+			group = &ReviewDuplicateGroup{Key: key, Review: review}
+			byKey[key] = group
+			keys = append(keys, key)
+		}
+		group.Rows = append(group.Rows, i)
+	}
+
+	groups := []ReviewDuplicateGroup{}
+	for _, key := range keys {
+		if len(byKey[key].Rows) > 1 {
+			groups = append(groups, *byKey[key])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+// dupApp returns a free app updated on the given "Last Updated" date, cleaned
+// the way ParseApps cleans cached rows
+func dupApp(name, category, updated string) App {
+	return normalizeApp(App{Name: name, Category: category, Rating: 4, Size: "1M", Installs: "1,000+", Type: "Free", Price: "0",
+		ContentRating: "Everyone", Genres: "Tools", LastUpdated: updated, CurrentVer: "1.0", AndroidVer: "4.1 and up"})
+}
+
+func TestMostRecent(t *testing.T) {
+	tests := []struct {
+		name  string
+		dates []string
+		want  int
+	}{
+		{"newest wins", []string{"May 2, 2018", "July 1, 2018", "January 3, 2018"}, 1},
+		{"ties go to the later row", []string{"May 2, 2018", "May 2, 2018"}, 1},
+		{"unparseable dates sort first", []string{"yesterday", "January 1, 2010"}, 1},
+		{"unparseable later row loses", []string{"January 1, 2010", "Varies with device"}, 0},
+		{"only unparseable dates, the later row", []string{"nan", ""}, 1},
+	}
+	for _, tt := range tests {
+		apps := make([]App, len(tt.dates))
+		for i, date := range tt.dates {
+			apps[i] = dupApp("Maps", "TRAVEL", date)
+		}
+		if got := mostRecent(apps); got != tt.want {
+			t.Errorf("%s: mostRecent = %d; want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyDuplicatePolicy(t *testing.T) {
+	stored := []App{
+		dupApp("Maps", "TRAVEL", "May 2, 2018"),
+		dupApp("Mail", "COMMUNICATION", "May 2, 2018"),
+		dupApp("maps", "travel", "January 1, 2018"),
+		dupApp("Notes", "PRODUCTIVITY", "May 2, 2018"),
+	}
+
+	tests := []struct {
+		name    string
+		policy  string
+		app     App
+		wantErr string
+		want    []string // names and dates of the merged rows, nil when appended
+	}{
+		{"new app is appended", DuplicatePolicyReject, dupApp("Zoom", "BUSINESS", "May 2, 2018"), "", nil},
+		{"exact duplicate is rejected", DuplicatePolicyReject, stored[0], "exact duplicate of row 0", nil},
+		{"near duplicate is rejected", DuplicatePolicyReject, dupApp("MAPS!", "Travel", "June 1, 2018"), "near duplicate of row 0", nil},
+		{"allow appends duplicates", DuplicatePolicyAllow, stored[0], "", nil},
+		{"newer row wins at the first match", DuplicatePolicyMerge, dupApp("Maps", "TRAVEL", "June 1, 2018"),
+			"", []string{"Maps June 1, 2018", "Mail May 2, 2018", "Notes May 2, 2018"}},
+		{"stored row wins over an older one", DuplicatePolicyMerge, dupApp("Maps", "TRAVEL", "March 1, 2017"),
+			"", []string{"Maps May 2, 2018", "Mail May 2, 2018", "Notes May 2, 2018"}},
+		{"tie goes to the submitted row", DuplicatePolicyMerge, dupApp("MAPS", "TRAVEL", "May 2, 2018"),
+			"", []string{"MAPS May 2, 2018", "Mail May 2, 2018", "Notes May 2, 2018"}},
+	}
+	for _, tt := range tests {
+		am := NewAppModel(zap.NewNop(), config.AppConfig{DuplicatePolicy: tt.policy})
+		merge, err := am.applyDuplicatePolicy(stored, tt.app)
+		if tt.wantErr != "" {
+			if !errors.Is(err, ErrDuplicateApp) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v; want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if tt.want == nil {
+			if merge != nil {
+				t.Errorf("%s: merged %+v; want the app appended", tt.name, merge.apps)
+			}
+			continue
+		}
+		if merge == nil {
+			t.Errorf("%s: appended; want a merge", tt.name)
+			continue
+		}
+		got := make([]string, len(merge.apps))
+		for i, app := range merge.apps {
+			got[i] = app.Name + " " + app.LastUpdated
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: merged rows = %q; want %q", tt.name, got, tt.want)
+		}
+		if len(merge.replaced) != 2 {
+			t.Errorf("%s: replaced %d rows; want both stored Maps rows", tt.name, len(merge.replaced))
+		}
+	}
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	apps := []App{
+		dupApp("Maps", "TRAVEL", "May 2, 2018"),
+		dupApp("Mail", "COMMUNICATION", "May 2, 2018"),
+		dupApp("Maps", "TRAVEL", "May 2, 2018"),
+		dupApp("Notes", "PRODUCTIVITY", "May 2, 2018"),
+		dupApp("notes", "Productivity", "June 1, 2018"),
+	}
+	groups := groupDuplicates(apps)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups; want 2: %+v", len(groups), groups)
+	}
+	if groups[0].Kind != DuplicateExact || groups[0].Rows[0].Row != 0 || groups[0].Rows[1].Row != 2 {
+		t.Errorf("first group = %+v; want rows 0 and 2 as exact duplicates", groups[0])
+	}
+	if groups[1].Kind != DuplicateNear || groups[1].Key != "notes|productivity" {
+		t.Errorf("second group = %+v; want the notes rows as near duplicates", groups[1])
+	}
+}
+
+func TestMergeDuplicatesKeepsTheFirstPosition(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+	}
+	rows := []string{
+		`Maps,TRAVEL,4.3,100,10M,"1,000+",Free,0,Everyone,Travel,"January 1, 2018",1.0,4.1 and up`,
+		`Mail,COMMUNICATION,4.1,200,8M,"1,000+",Free,0,Everyone,Communication,"May 2, 2018",1.0,4.1 and up`,
+		`maps,Travel,4.5,120,10M,"1,000+",Free,0,Everyone,Travel,"June 1, 2018",2.0,4.1 and up`,
+		`Notes,PRODUCTIVITY,4.5,50,3M,"1,000+",Free,0,Everyone,Productivity,"May 2, 2018",1.0,4.1 and up`,
+		`Maps,TRAVEL,4.0,90,10M,"1,000+",Free,0,Everyone,Travel,"bad date",0.9,4.1 and up`,
+	}
+	if err := os.WriteFile(cfg.CSVFilePath, []byte(appsHeader+strings.Join(rows, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	if _, err := am.MergeDuplicates(ctx, "nothing|here"); !errors.Is(err, ErrDuplicateGroupNotFound) {
+		t.Errorf("MergeDuplicates of an unknown key = %v; want ErrDuplicateGroupNotFound", err)
+	}
+
+	removed, err := am.MergeDuplicates(ctx, "maps|travel")
+	if err != nil || removed != 2 {
+		t.Fatalf("MergeDuplicates = %d, %v; want 2 rows removed", removed, err)
+	}
+	apps, err := am.ParseApps(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(apps))
+	for i, app := range apps {
+		got[i] = app.Name + " " + app.CurrentVer
+	}
+	want := []string{"maps 2.0", "Mail 1.0", "Notes 1.0"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("apps after merge = %q; want the June row in the first row's place", got)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+)
+
+// Sentinel errors returned by the models. Callers match them with
+// errors.Is, they may be wrapped with additional detail.
+var (
+	ErrAppNotFound             = errors.New(constants.AppNotFoundErrorMessage)
+	ErrReviewsNotFound         = errors.New(constants.ErrReviewsNotFound)
+	ErrInvalidPrice            = errors.New(constants.ErrInvalidPriceValue)
+	ErrFilePathNotConfigured   = errors.New(constants.ErrFilePathNotConfigured)
+	ErrDuplicateApp            = errors.New(constants.ErrDuplicateApp)
+	ErrDuplicateReview         = errors.New(constants.ErrDuplicateReview)
+	ErrDuplicateGroupNotFound  = errors.New(constants.ErrDuplicateGroupNotFound)
+	ErrTrashEntryNotFound      = errors.New(constants.ErrTrashEntryNotFound)
+	ErrShuttingDown            = errors.New(constants.ErrShuttingDown)
+	ErrUnsupportedFormat       = errors.New(constants.ErrUnsupportedFormat)
+	ErrNoKnownColumns          = errors.New(constants.ErrNoKnownColumns)
+	ErrQuarantineUnrecoverable = errors.New(constants.ErrQuarantineUnrecoverable)
+	ErrImportIncomplete        = errors.New(constants.ErrImportIncomplete)
+)
@@ -0,0 +1,301 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
+)
+
+// ImportReport summarises an import. Invalid rows failed to decode or
+// validate, rejected rows were refused by the duplicate policy.
+type ImportReport struct {
+	Entity   string     `json:"entity"`
+	Format   string     `json:"format"`
+	DryRun   bool       `json:"dry_run"`
+	Rows     int        `json:"rows"`
+	Imported int        `json:"imported"`
+	Merged   int        `json:"merged"`
+	Invalid  int        `json:"invalid"`
+	Rejected int        `json:"rejected"`
+	Errors   []RowError `json:"errors"`
+}
+
+// newImportReport: Starts the report of an import from the decoded file
+func newImportReport(entity, format string, dryRun bool, rows int, rowErrors []RowError) ImportReport {
+	return ImportReport{
+		Entity:  entity,
+		Format:  format,
+		DryRun:  dryRun,
+		Rows:    rows + len(rowErrors),
+		Invalid: len(rowErrors),
+		Errors:  append([]RowError{}, rowErrors...),
+	}
+}
+
+// reject: Records a row the duplicate policy refused
+func (r *ImportReport) reject(line int, err error) {
+	r.Rejected++
+	r.Errors = append(r.Errors, RowError{Line: line, Message: err.Error()})
+}
+
+// sortErrors: Orders the row errors by line
+func (r *ImportReport) sortErrors() {
+	sort.SliceStable(r.Errors, func(i, j int) bool { return r.Errors[i].Line < r.Errors[j].Line })
+}
+
+// ImportSource is a file to import, read in Format. Name labels its errors.
+type ImportSource struct {
+	Name   string
+	Reader io.Reader
+	Format string
+}
+
+// failed: Counts the rows of reports that were not imported
+func failed(reports []ImportReport) int {
+	var rows int
+	for _, report := range reports {
+		rows += report.Invalid + report.Rejected
+	}
+	return rows
+}
+
+// decodeSources: Decodes every source as T before anything is imported and
+// starts their reports. The first source that cannot be read fails them all.
+func decodeSources[T any](entity string, sources []ImportSource, opts csvOptions, dryRun bool) ([][]numbered[T], []ImportReport, error) {
+	rows := make([][]numbered[T], 0, len(sources))
+	reports := make([]ImportReport, 0, len(sources))
+	for _, source := range sources {
+		decoded, rowErrors, err := decodeRows[T](source.Reader, source.Format, opts)
+		if err != nil {
+			if source.Name != "" {
+				err = fmt.Errorf("%s: %w", source.Name, err)
+			}
+			return nil, nil, err
+		}
+		rows = append(rows, decoded)
+		reports = append(reports, newImportReport(entity, source.Format, dryRun, len(decoded), rowErrors))
+	}
+	return rows, reports, nil
+}
+
+// Import: Reads apps from r in format and adds the valid ones, see ImportAll
+func (am *AppModel) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (ImportReport, error) {
+	reports, err := am.ImportAll(ctx, []ImportSource{{Reader: r, Format: format}}, dryRun, false)
+	if len(reports) == 0 {
+		return ImportReport{}, err
+	}
+	return reports[0], err
+}
+
+// ImportAll: Reads apps from every source and adds the valid ones,
+// applying the duplicate policy to each row against the stored apps and
+// the rows of the sources before it. Every source is decoded before the
+// CSV file is rewritten, once for the whole import. With strict nothing is
+// written unless every row can be imported, with dryRun nothing is
+// written. Returns a report per source.
+func (am *AppModel) ImportAll(ctx context.Context, sources []ImportSource, dryRun, strict bool) (reports []ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "AppModel.Import", trace.WithAttributes(
+		attribute.Int("import.files", len(sources)),
+		attribute.Bool("import.dry_run", dryRun),
+		attribute.Bool("import.strict", strict),
+	))
+	imported := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("import.imported", imported))
+		tracing.End(span, err)
+	}()
+
+	sourceRows, reports, err := decodeSources[App](EntityApp, sources, transferCSV(am.config), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range reports {
+			reports[i].sortErrors()
+		}
+	}()
+
+	done, err := beginWrite()
+	if err != nil {
+		return reports, err
+	}
+	defer done()
+
+	appMutex.Lock()
+	defer appMutex.Unlock()
+
+	apps, err := am.cachedApps(ctx)
+	if err != nil {
+		am.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return reports, err
+	}
+
+	current := append(make([]App, 0, len(apps)), apps...)
+	var replaced, added []App
+	for i, rows := range sourceRows {
+		report := &reports[i]
+		for _, row := range rows {
+			merge, err := am.applyDuplicatePolicy(current, row.row)
+			if err != nil {
+				report.reject(row.line, err)
+				continue
+			}
+			if merge != nil {
+				current = merge.apps
+				replaced = append(replaced, merge.replaced...)
+				added = append(added, merge.kept)
+				report.Merged++
+				continue
+			}
+			current = append(current, normalizeApp(row.row))
+			added = append(added, normalizeApp(row.row))
+			report.Imported++
+			imported++
+		}
+	}
+
+	if rows := failed(reports); strict && rows > 0 {
+		return reports, fmt.Errorf("%w: %d rows failed", ErrImportIncomplete, rows)
+	}
+	if dryRun || len(added) == 0 {
+		return reports, nil
+	}
+	if err := am.writeApps(ctx, current); err != nil {
+		return reports, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionImport,
+		Entity: EntityApp,
+		Before: replaced,
+		After:  added,
+	})
+	return reports, nil
+}
+
+// Import: Reads reviews from r in format and adds the valid ones, see ImportAll
+func (rm *ReviewModel) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (ImportReport, error) {
+	reports, err := rm.ImportAll(ctx, []ImportSource{{Reader: r, Format: format}}, dryRun, false)
+	if len(reports) == 0 {
+		return ImportReport{}, err
+	}
+	return reports[0], err
+}
+
+// ImportAll: Reads reviews from every source and adds the valid ones,
+// applying the duplicate policy to each row against the stored reviews
+// and the rows of the sources before it. Every source is decoded before
+// the CSV file is rewritten, once for the whole import. With strict
+// nothing is written unless every row can be imported, with dryRun
+// nothing is written. Returns a report per source.
+func (rm *ReviewModel) ImportAll(ctx context.Context, sources []ImportSource, dryRun, strict bool) (reports []ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.Import", trace.WithAttributes(
+		attribute.Int("import.files", len(sources)),
+		attribute.Bool("import.dry_run", dryRun),
+		attribute.Bool("import.strict", strict),
+	))
+	imported := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("import.imported", imported))
+		tracing.End(span, err)
+	}()
+
+	sourceRows, reports, err := decodeSources[Review](EntityReview, sources, transferCSV(rm.config), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range reports {
+			reports[i].sortErrors()
+		}
+	}()
+
+	done, err := beginWrite()
+	if err != nil {
+		return reports, err
+	}
+	defer done()
+
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	reviews, err := rm.cachedReviews(ctx)
+	if err != nil {
+		rm.log(ctx).Error(constants.ErrParsingReviewsCSV, zap.Error(err))
+		return reports, err
+	}
+
+	current := append(make([]Review, 0, len(reviews)), reviews...)
+	var added []Review
+	for i, rows := range sourceRows {
+		report := &reports[i]
+		for _, row := range rows {
+			duplicate, err := rm.applyDuplicatePolicy(current, row.row)
+			if err != nil {
+				report.reject(row.line, err)
+				continue
+			}
+			if duplicate {
+				// The identical review is already stored
+				report.Merged++
+				continue
+			}
+			current = append(current, row.row)
+			added = append(added, row.row)
+			report.Imported++
+			imported++
+		}
+	}
+
+	if rows := failed(reports); strict && rows > 0 {
+		return reports, fmt.Errorf("%w: %d rows failed", ErrImportIncomplete, rows)
+	}
+	if dryRun || len(added) == 0 {
+		return reports, nil
+	}
+	if err := rm.writeReviews(ctx, current); err != nil {
+		return reports, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionImport,
+		Entity: EntityReview,
+		After:  added,
+	})
+	return reports, nil
+}
+
+// Export: Writes the apps matching the list filters to w in format.
+// A limit of 0 exports every matching app.
+func (am *AppModel) Export(ctx context.Context, w io.Writer, format string, limit, page int, priceFilter string, asOf time.Time) (int, error) {
+	apps, err := am.FilterApps(ctx, priceFilter, asOf)
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 {
+		offset := (page - 1) * limit
+		if offset >= len(apps) {
+			apps = nil
+		} else {
+			apps = apps[offset:min(offset+limit, len(apps))]
+		}
+	}
+	return len(apps), encodeRows(w, apps, format, transferCSV(am.config))
+}
+
+// Export: Writes the reviews matching the list filters to w in format
+func (rm *ReviewModel) Export(ctx context.Context, w io.Writer, format, appName, sentiment string, polarityMin, polarityMax float64, asOf time.Time) (int, error) {
+	reviews, err := rm.ListReviews(ctx, appName, sentiment, polarityMin, polarityMax, asOf)
+	if err != nil && !errors.Is(err, ErrReviewsNotFound) {
+		return 0, err
+	}
+	return len(reviews), encodeRows(w, reviews, format, transferCSV(rm.config))
+}
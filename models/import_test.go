@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestImportAllAppliesThePolicyAcrossSources(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+		DuplicatePolicy:    DuplicatePolicyReject,
+	}
+	if err := os.WriteFile(cfg.CSVFilePath, appsFile(3), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	zoom := appsHeader + `Zoom,BUSINESS,4.0,300,20M,"1,000+",Free,0,Everyone,Business,"May 2, 2018",1.0,4.1 and up` + "\n"
+	sources := func() []ImportSource {
+		return []ImportSource{
+			{Name: "first.csv", Reader: strings.NewReader(zoom), Format: FormatCSV},
+			{Name: "second.csv", Reader: strings.NewReader(zoom), Format: FormatCSV},
+		}
+	}
+	before, _ := os.ReadFile(cfg.CSVFilePath)
+
+	reports, err := am.ImportAll(ctx, sources(), false, true)
+	if !errors.Is(err, ErrImportIncomplete) {
+		t.Fatalf("strict ImportAll = %v; want ErrImportIncomplete", err)
+	}
+	if len(reports) != 2 || reports[0].Imported != 1 || reports[1].Rejected != 1 {
+		t.Fatalf("reports = %+v; want the second file's copy rejected", reports)
+	}
+	if after, _ := os.ReadFile(cfg.CSVFilePath); string(after) != string(before) {
+		t.Fatal("a strict import with a rejected row wrote the file")
+	}
+
+	reports, err = am.ImportAll(ctx, sources(), false, false)
+	if err != nil || reports[0].Imported != 1 || reports[1].Rejected != 1 {
+		t.Fatalf("ImportAll = %+v, %v; want one copy imported and one rejected", reports, err)
+	}
+	if got := strings.Count(string(mustRead(t, cfg.CSVFilePath)), "Zoom,"); got != 1 {
+		t.Errorf("the file holds %d copies of Zoom; want 1", got)
+	}
+
+	// A source that cannot be read fails the import before anything is written
+	bad := []ImportSource{{Name: "good.csv", Reader: strings.NewReader(zoom), Format: FormatCSV}, {Name: "bad.xml", Reader: strings.NewReader(""), Format: "xml"}}
+	if _, err := am.ImportAll(ctx, bad, false, false); !errors.Is(err, ErrUnsupportedFormat) || !strings.Contains(err.Error(), "bad.xml") {
+		t.Errorf("ImportAll with an unreadable source = %v; want ErrUnsupportedFormat naming it", err)
+	}
+}
+
+// mustRead returns the content of the file at path
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
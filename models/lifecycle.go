@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"sync"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Global Write Tracking Variables
+var (
+	writes       sync.WaitGroup
+	writesMutex  sync.Mutex
+	writesClosed bool
+)
+
+// beginWrite: Registers an in-flight mutation so Shutdown waits for it.
+// It fails with ErrShuttingDown once Shutdown has started.
+func beginWrite() (done func(), err error) {
+	writesMutex.Lock()
+	defer writesMutex.Unlock()
+
+	if writesClosed {
+		return nil, ErrShuttingDown
+	}
+	writes.Add(1)
+	return writes.Done, nil
+}
+
+// Shutdown: Rejects new mutations, waits until the in-flight ones are
+// written or ctx is done and syncs the data files to disk
+func Shutdown(ctx context.Context, cfg config.AppConfig) error {
+	writesMutex.Lock()
+	writesClosed = true
+	writesMutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		writes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for _, path := range []string{cfg.CSVFilePath, cfg.ReviewFilePath, cfg.TrashFilePath} {
+		if path == "" {
+			continue
+		}
+		if err := utils.SyncFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+)
+
+// CompactResult reports what compacting one CSV file did
+type CompactResult struct {
+	Path        string `json:"path"`
+	Rows        int    `json:"rows"`
+	BytesBefore int64  `json:"bytes_before"`
+	BytesAfter  int64  `json:"bytes_after"`
+}
+
+// snapshotLayout names snapshot directories, sortable by time
+const snapshotLayout = "20060102T150405Z"
+
+// ReloadDatasets: Reads the apps and reviews CSV files again, as SIGHUP
+// and the admin reload ask for, and returns the rows loaded by entity
+func ReloadDatasets(ctx context.Context, logger *zap.Logger, cfg config.AppConfig) (map[string]int, error) {
+	rows := map[string]int{}
+	apps, err := NewAppModel(logger, cfg).Reload(ctx)
+	if err != nil {
+		return rows, fmt.Errorf("apps: %w", err)
+	}
+	rows[EntityApp] = apps
+
+	reviews, err := NewReviewModel(logger, cfg).Reload(ctx)
+	if err != nil {
+		return rows, fmt.Errorf("reviews: %w", err)
+	}
+	rows[EntityReview] = reviews
+	return rows, nil
+}
+
+// Compact: Rewrites the apps CSV from its parsed rows, folding appended
+// rows into the normalized format written by every rewrite
+func (am *AppModel) Compact(ctx context.Context) (CompactResult, error) {
+	done, err := beginWrite()
+	if err != nil {
+		return CompactResult{}, err
+	}
+	defer done()
+
+	appMutex.Lock()
+	defer appMutex.Unlock()
+
+	result := CompactResult{Path: am.config.CSVFilePath, BytesBefore: fileSize(am.config.CSVFilePath)}
+	apps, err := am.ParseApps(ctx)
+	if err != nil {
+		am.log(ctx).Error("Error parsing apps for compaction", zap.Error(err))
+		return result, err
+	}
+	// The quarantine now holds positions among the parsed rows
+	appCache = apps
+	if err := am.writeApps(ctx, apps); err != nil {
+		return result, err
+	}
+
+	result.Rows = len(apps)
+	result.BytesAfter = fileSize(am.config.CSVFilePath)
+	return result, nil
+}
+
+// Compact: Rewrites the reviews CSV from its parsed rows
+func (rm *ReviewModel) Compact(ctx context.Context) (CompactResult, error) {
+	done, err := beginWrite()
+	if err != nil {
+		return CompactResult{}, err
+	}
+	defer done()
+
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	result := CompactResult{Path: rm.config.ReviewFilePath, BytesBefore: fileSize(rm.config.ReviewFilePath)}
+	reviews, err := rm.ParseReviews(ctx)
+	if err != nil {
+		rm.log(ctx).Error("Error parsing reviews for compaction", zap.Error(err))
+		return result, err
+	}
+	// The quarantine now holds positions among the parsed rows
+	reviewCache = reviews
+	if err := rm.writeReviews(ctx, reviews); err != nil {
+		return result, err
+	}
+
+	result.Rows = len(reviews)
+	result.BytesAfter = fileSize(rm.config.ReviewFilePath)
+	return result, nil
+}
+
+type SnapshotModel struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewSnapshotModel initializes a new SnapshotModel instance
+func NewSnapshotModel(logger *zap.Logger, config config.AppConfig) *SnapshotModel {
+	return &SnapshotModel{
+		logger: logger,
+		config: config,
+	}
+}
+
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (sm *SnapshotModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, sm.logger)
+}
+
+// Take: Copies the apps and reviews CSV files into a new timestamped
+// directory under SnapshotDir and removes the oldest snapshots beyond
+// SnapshotRetain. It returns the new directory.
+func (sm *SnapshotModel) Take(ctx context.Context) (string, error) {
+	if sm.config.SnapshotDir == "" {
+		return "", fmt.Errorf("snapshot %w", ErrFilePathNotConfigured)
+	}
+
+	dir := filepath.Join(sm.config.SnapshotDir, time.Now().UTC().Format(snapshotLayout))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// Hold both read locks so the files match each other
+	appMutex.RLock()
+	defer appMutex.RUnlock()
+	reviewMutex.RLock()
+	defer reviewMutex.RUnlock()
+
+	for _, path := range []string{sm.config.CSVFilePath, sm.config.ReviewFilePath} {
+		if path == "" {
+			continue
+		}
+		if err := copyFile(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+			sm.log(ctx).Error("Error copying file into snapshot", zap.String("path", path), zap.Error(err))
+			return "", err
+		}
+	}
+
+	if err := sm.prune(); err != nil {
+		sm.log(ctx).Error("Error removing old snapshots", zap.Error(err))
+	}
+	return dir, nil
+}
+
+// prune: Removes the oldest snapshot directories beyond SnapshotRetain
+func (sm *SnapshotModel) prune() error {
+	if sm.config.SnapshotRetain <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(sm.config.SnapshotDir)
+	if err != nil {
+		return err
+	}
+	var snapshots []string
+	for _, entry := range entries {
+		if _, err := time.Parse(snapshotLayout, entry.Name()); entry.IsDir() && err == nil {
+			snapshots = append(snapshots, entry.Name())
+		}
+	}
+	sort.Strings(snapshots)
+
+	for len(snapshots) > sm.config.SnapshotRetain {
+		if err := os.RemoveAll(filepath.Join(sm.config.SnapshotDir, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// copyFile: Copies src to dst and syncs it to disk
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// fileSize: Returns the size of the file at path, or 0 when it cannot be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
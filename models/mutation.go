@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Mutation actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionMerge   = "merge"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+	ActionImport  = "import"
+	// The dataset was read from disk again, nothing was changed
+	ActionReload = "reload"
+)
+
+// Mutated entities
+const (
+	EntityApp     = "app"
+	EntityReview  = "review"
+	EntityTrash   = "trash"
+	EntityDataset = "dataset"
+)
+
+// Mutation describes one committed change to the dataset
+type Mutation struct {
+	Time      time.Time   `json:"time"`
+	Actor     string      `json:"actor"`
+	RequestID string      `json:"request_id,omitempty"`
+	Action    string      `json:"action"`
+	Entity    string      `json:"entity"`
+	Key       string      `json:"key"`
+	Before    interface{} `json:"before,omitempty"`
+	After     interface{} `json:"after,omitempty"`
+}
+
+// MutationObserver is called after a mutation was written.
+// Observers run synchronously and must not call back into the models.
+type MutationObserver func(ctx context.Context, mutation Mutation)
+
+var (
+	observers     []MutationObserver
+	observerMutex sync.RWMutex
+)
+
+// OnMutation: Registers an observer for every committed mutation
+func OnMutation(observer MutationObserver) {
+	observerMutex.Lock()
+	defer observerMutex.Unlock()
+	observers = append(observers, observer)
+}
+
+// notifyMutation: Stamps mutation with the time, actor and request ID
+// carried by ctx and hands it to the registered observers
+func notifyMutation(ctx context.Context, mutation Mutation) {
+	mutation.Time = time.Now().UTC()
+	mutation.Actor = utils.ActorFromContext(ctx)
+	mutation.RequestID = utils.RequestIDFromContext(ctx)
+
+	observerMutex.RLock()
+	defer observerMutex.RUnlock()
+	for _, observer := range observers {
+		observer(ctx, mutation)
+	}
+}
@@ -0,0 +1,338 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jszwec/csvutil"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Severities of data quality issues. Errors are rows the server cannot
+// load, warnings are rows it loads or skips on purpose.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Data quality rules. Struct tag failures use the name of the failed tag,
+// such as required or gte.
+const (
+	RuleHeaderMissing   = "header_missing"
+	RuleHeaderUnknown   = "header_unknown"
+	RuleHeaderDuplicate = "header_duplicate"
+	RuleSyntax          = "csv_syntax"
+	RuleFieldCount      = "field_count"
+	RuleType            = "type"
+	RuleNaN             = "nan"
+	RuleDuplicate       = "duplicate"
+	RuleUnknownApp      = "unknown_app"
+)
+
+// Issue is one rule a row, or the header on line 1, breaks
+type Issue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   string `json:"column,omitempty"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// FileSummary counts the rows of a checked file. Valid rows have no errors.
+type FileSummary struct {
+	File   string `json:"file"`
+	Entity string `json:"entity"`
+	Rows   int    `json:"rows"`
+	Valid  int    `json:"valid"`
+}
+
+// QualityReport is the result of checking the apps and reviews datasets
+type QualityReport struct {
+	Files    []FileSummary  `json:"files"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Rules    map[string]int `json:"rules"`
+	Issues   []Issue        `json:"issues"`
+}
+
+// add: Records issues and counts them by severity and rule
+func (r *QualityReport) add(issues ...Issue) {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			r.Errors++
+		} else {
+			r.Warnings++
+		}
+		r.Rules[issue.Rule]++
+		r.Issues = append(r.Issues, issue)
+	}
+}
+
+// ValidateDatasets: Checks the apps and reviews CSV files row by row: the
+// header against the struct tags, the number of fields, field types,
+// validation rules, duplicates and reviews of apps missing from appsPath.
+// Files are read in the dialects of cfg, their columns matched by name
+// after resolving aliases. An error is only returned when a file cannot be
+// read at all.
+func ValidateDatasets(cfg config.AppConfig, appsPath, reviewsPath string) (QualityReport, error) {
+	report := QualityReport{Rules: map[string]int{}, Issues: []Issue{}}
+
+	apps := map[string]bool{}
+	firstLine := map[string]int{}
+	summary, issues, err := checkFile(appsPath, EntityApp, appsCSV(cfg), func(file string, line int, app App) []Issue {
+		apps[app.Name] = true
+		key := duplicateKey(app)
+		if first, ok := firstLine[key]; ok {
+			return []Issue{{
+				File:     file,
+				Line:     line,
+				Column:   "App",
+				Rule:     RuleDuplicate,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%q duplicates the app on line %d", app.Name, first),
+			}}
+		}
+		firstLine[key] = line
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Files = append(report.Files, summary)
+	report.add(issues...)
+
+	summary, issues, err = checkFile(reviewsPath, EntityReview, reviewsCSV(cfg), func(file string, line int, review Review) []Issue {
+		if !apps[review.App] {
+			return []Issue{{
+				File:     file,
+				Line:     line,
+				Column:   "App",
+				Rule:     RuleUnknownApp,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%q is not in %s", review.App, filepath.Base(appsPath)),
+			}}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Files = append(report.Files, summary)
+	report.add(issues...)
+
+	return report, nil
+}
+
+// checkFile: Checks the rows of the CSV file at path as T, read with opts.
+// check runs on rows that decoded and validated, for the rules spanning rows.
+func checkFile[T any](path, entity string, opts csvOptions, check func(file string, line int, row T) []Issue) (FileSummary, []Issue, error) {
+	name := filepath.Base(path)
+	summary := FileSummary{File: name, Entity: entity}
+	if path == "" {
+		return summary, nil, fmt.Errorf("%s %w", entity, ErrFilePathNotConfigured)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return summary, nil, err
+	}
+	defer file.Close()
+
+	reader := opts.dialect.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return summary, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	schema := newCSVSchema[T](header, opts.aliases)
+	issues, missing := checkHeader[T](name, schema, opts.aliases)
+
+	// Rows cannot be decoded without a known column, the header issues say why
+	if len(schema.decoderHeader()) == 0 {
+		for {
+			if _, err := reader.Read(); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil && !errors.As(err, new(*csv.ParseError)) {
+				return summary, issues, fmt.Errorf("%s: %w", name, err)
+			}
+			summary.Rows++
+		}
+		return summary, issues, nil
+	}
+
+	columns := csvColumns[T]()
+	err = eachCSVRow(reader, schema, func(line int, row T) {
+		summary.Rows++
+		// The loader skips reviews marked "nan" before validating them
+		if review, ok := any(row).(Review); ok && !loadableReview(review) {
+			issues = append(issues, Issue{File: name, Line: line, Rule: RuleNaN, Severity: SeverityWarning, Message: "review has nan values and is skipped when loading"})
+			summary.Valid++
+			return
+		}
+
+		// Other nan values are unknown, they load as they are
+		nan := nanColumns(row, columns)
+		for _, column := range sortedKeys(nan) {
+			issues = append(issues, Issue{File: name, Line: line, Column: column, Rule: RuleNaN, Severity: SeverityWarning, Message: "value is nan and loaded as unknown"})
+		}
+
+		rowIssues := validateRow(name, line, row, columns, missing, nan)
+		if len(rowIssues) == 0 {
+			rowIssues = check(name, line, row)
+		}
+		issues = append(issues, rowIssues...)
+		if !hasError(rowIssues) {
+			summary.Valid++
+		}
+	}, func(line int, _ []string, err error) {
+		summary.Rows++
+		issues = append(issues, rowIssue(name, line, err))
+	})
+	if err != nil {
+		return summary, issues, fmt.Errorf("%s: %w", name, err)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return summary, issues, nil
+}
+
+// checkHeader: Compares the columns of schema with the fields of T. It
+// returns the issues and the fields no column fills.
+func checkHeader[T any](file string, schema csvSchema, aliases map[string]string) ([]Issue, map[string]bool) {
+	var issues []Issue
+	tags, _ := csvutil.Header(*new(T), "csv")
+	known := map[string]bool{}
+	for _, tag := range tags {
+		known[tag] = true
+	}
+
+	for i, column := range schema.header {
+		if schema.fields[i] != "" {
+			continue
+		}
+		name := strings.TrimSpace(column)
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if known[name] {
+			issues = append(issues, Issue{File: file, Line: 1, Column: column, Rule: RuleHeaderDuplicate, Severity: SeverityError, Message: fmt.Sprintf("%s is already read from another column, this one is kept as metadata", name)})
+		} else {
+			issues = append(issues, Issue{File: file, Line: 1, Column: column, Rule: RuleHeaderUnknown, Severity: SeverityWarning, Message: "column is kept as metadata"})
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, tag := range schema.missing(tags) {
+		missing[tag] = true
+		issues = append(issues, Issue{File: file, Line: 1, Column: tag, Rule: RuleHeaderMissing, Severity: SeverityError, Message: "expected column is missing"})
+	}
+	return issues, missing
+}
+
+// validateRow: Returns an issue per struct tag rule row breaks, named by
+// CSV column. Columns missing from the header are already reported once
+// and columns holding nan are reported as such, both are skipped here.
+func validateRow[T any](file string, line int, row T, columns map[string]string, missing, nan map[string]bool) []Issue {
+	var validationErr *utils.ValidationError
+	if err := utils.ValidateStruct(row); !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	var issues []Issue
+	for _, field := range validationErr.Fields {
+		column := columns[field.Field]
+		if missing[column] || nan[column] {
+			continue
+		}
+		// Messages start with the Go field name, the file knows the column
+		message := field.Message
+		if column != "" && strings.HasPrefix(message, field.JSONName) {
+			message = column + strings.TrimPrefix(message, field.JSONName)
+		}
+		issues = append(issues, Issue{File: file, Line: line, Column: column, Rule: field.Rule, Severity: SeverityError, Message: message})
+	}
+	return issues
+}
+
+// nanColumns: Returns the CSV columns of row holding nan, the marker the
+// Play Store dataset uses for values nobody filled in
+func nanColumns[T any](row T, columns map[string]string) map[string]bool {
+	nan := map[string]bool{}
+	value := reflect.ValueOf(row)
+	for i := 0; i < value.NumField(); i++ {
+		column, ok := columns[value.Type().Field(i).Name]
+		if !ok {
+			continue
+		}
+		switch field := value.Field(i); field.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if isNaN(field.Float()) {
+				nan[column] = true
+			}
+		case reflect.String:
+			if strings.EqualFold(strings.TrimSpace(field.String()), "nan") {
+				nan[column] = true
+			}
+		}
+	}
+	return nan
+}
+
+// sortedKeys: Returns the keys of set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// csvColumns: Maps the fields of T to their CSV columns
+func csvColumns[T any]() map[string]string {
+	columns := map[string]string{}
+	typ := reflect.TypeOf(*new(T))
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if column := field.Tag.Get("csv"); column != "" {
+			columns[field.Name] = column
+		}
+	}
+	return columns
+}
+
+// rowIssue: Returns the issue of a record eachCSVRow could not decode
+func rowIssue(file string, line int, err error) Issue {
+	issue := Issue{File: file, Line: line, Rule: RuleType, Severity: SeverityError, Message: err.Error()}
+	var parseErr *csv.ParseError
+	var decodeErr *csvutil.DecodeError
+	switch {
+	case errors.As(err, &parseErr):
+		issue.Rule = RuleSyntax
+		issue.Message = parseErr.Err.Error()
+	case errors.Is(err, errFieldCount):
+		issue.Rule = RuleFieldCount
+	case errors.As(err, &decodeErr):
+		issue.Column = decodeErr.Field
+		issue.Message = decodeErr.Err.Error()
+	}
+	return issue
+}
+
+// hasError: Reports whether issues holds an error
+func hasError(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
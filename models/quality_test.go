@@ -0,0 +1,114 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+// Rows as they are in the Play Store dataset, quirks included
+const playStoreApps = `App,Category,Rating,Reviews,Size,Installs,Type,Price,Content Rating,Genres,Last Updated,Current Ver,Android Ver
+Photo Editor & Candy Camera & Grid & ScrapBook,ART_AND_DESIGN,4.1,159,19M,"10,000+",Free,0,Everyone,Art & Design,"January 7, 2018",1.0.0,4.0.3 and up
+Coloring book moana,ART_AND_DESIGN,3.9,967,14M,"500,000+",Free,0,Everyone,Art & Design;Pretend Play,"January 15, 2018",2.0.0,4.0.3 and up
+Mcqueen Coloring pages,ART_AND_DESIGN,NaN,61,7.0M,"100,000+",Free,0,Everyone,Art & Design;Action & Adventure,"March 7, 2018",1.0.0,4.1 and up
+Hitman Sniper,GAME,4.6,408292,29M,"10,000,000+",Paid,$0.99,Mature 17+,Action,"July 12, 2018",1.7.110758,4.1 and up
+Wear OS by Google Smartwatch,COMMUNICATION,4.2,32,Varies with device,"10,000,000+",Free,0,Everyone,Communication,"August 1, 2018",Varies with device,4.4W and up
+Facebook Watch Faces,PERSONALIZATION,4.0,12,3.1M,"1,000+",Free,0,Everyone,Personalization,"May 2, 2018",1.2,4.4W - 8.0
+[substratum] Vacuum: P,PERSONALIZATION,4.4,230,11M,"1,000+",Paid,$1.49,Everyone,Personalization,"July 20, 2018",4.4,NaN
+Command & Conquer: Rivals,FAMILY,NaN,0,Varies with device,0,NaN,0,Everyone 10+,Strategy,"June 28, 2018",Varies with device,Varies with device
+Photo Editor & Candy Camera & Grid & ScrapBook,ART_AND_DESIGN,4.1,159,19M,"10,000+",Free,0,Everyone,Art & Design,"January 7, 2018",1.0.0,4.0.3 and up
+`
+
+const playStoreReviews = `App,Translated_Review,Sentiment,Sentiment_Polarity,Sentiment_Subjectivity
+Coloring book moana,A kid's excessive ads.,Negative,-0.25,1.0
+Coloring book moana,nan,nan,nan,nan
+Hitman Sniper,Great game,Positive,0.8,0.75
+`
+
+// writeDatasets writes apps and reviews to a temporary directory
+func writeDatasets(t *testing.T, apps, reviews string) (string, string) {
+	dir := t.TempDir()
+	appsPath := filepath.Join(dir, "apps.csv")
+	reviewsPath := filepath.Join(dir, "reviews.csv")
+	if err := os.WriteFile(appsPath, []byte(apps), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(reviewsPath, []byte(reviews), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return appsPath, reviewsPath
+}
+
+func TestValidateDatasetsAcceptsPlayStoreQuirks(t *testing.T) {
+	appsPath, reviewsPath := writeDatasets(t, playStoreApps, playStoreReviews)
+	report, err := ValidateDatasets(config.AppConfig{}, appsPath, reviewsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Errors != 0 {
+		t.Errorf("got %d errors; want none: %+v", report.Errors, report.Issues)
+	}
+	// Two nan ratings, a nan Android Ver, a nan Type, a nan review and a duplicate
+	if report.Rules[RuleNaN] != 5 || report.Rules[RuleDuplicate] != 1 || report.Warnings != 6 {
+		t.Errorf("rules = %v, %d warnings; want 5 nan and 1 duplicate", report.Rules, report.Warnings)
+	}
+	for _, issue := range report.Issues {
+		if issue.Rule == RuleNaN && issue.Line == 4 && issue.Column != "Rating" {
+			t.Errorf("nan rating reported on column %q; want Rating", issue.Column)
+		}
+	}
+}
+
+func TestValidateDatasetsNamesCSVColumns(t *testing.T) {
+	// The shifted row of the Play Store dataset
+	apps := strings.SplitN(playStoreApps, "\n", 2)[0] + "\n" +
+		`Broken Version,ART_AND_DESIGN,4.1,159,19M,"10,000+",Free,0,Everyone,Art & Design,"January 7, 2018",1.0.0,Android 4 or so` + "\n" +
+		`Life Made WI-Fi Touchscreen Photo Frame,1.9,19,3,1000+,Free,0,Everyone,,"February 11, 2018",1.0.19,4.0 and up` + "\n"
+	appsPath, reviewsPath := writeDatasets(t, apps, strings.SplitN(playStoreReviews, "\n", 2)[0]+"\n")
+
+	report, err := ValidateDatasets(config.AppConfig{}, appsPath, reviewsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Errors != 2 {
+		t.Fatalf("got %d errors; want the bad version and the shifted row: %+v", report.Errors, report.Issues)
+	}
+	version := report.Issues[0]
+	if version.Column != "Android Ver" || version.Rule != "android_version" || !strings.HasPrefix(version.Message, "Android Ver must be") {
+		t.Errorf("version issue = %+v; want it named by the Android Ver column", version)
+	}
+	if shifted := report.Issues[1]; shifted.Line != 3 || shifted.Rule != RuleFieldCount {
+		t.Errorf("shifted row issue = %+v; want a field count error on line 3", shifted)
+	}
+}
+
+func TestHeaderWithoutKnownColumns(t *testing.T) {
+	// Read with the wrong delimiter, the header is one unknown column
+	apps := strings.ReplaceAll(strings.SplitN(playStoreApps, "\n", 4)[0], ",", ";") + "\n" +
+		"Maps;TRAVEL;4.3;100;10M;1,000+;Free;0;Everyone;Travel;May 2, 2018;1.0;4.1 and up\n"
+	appsPath, reviewsPath := writeDatasets(t, apps, playStoreReviews)
+	cfg := config.AppConfig{CSVFilePath: appsPath}
+
+	report, err := ValidateDatasets(cfg, appsPath, reviewsPath)
+	if err != nil {
+		t.Fatalf("ValidateDatasets failed instead of reporting the header: %v", err)
+	}
+	if report.Rules[RuleHeaderMissing] != 13 || report.Files[0].Rows != 1 || report.Files[0].Valid != 0 {
+		t.Errorf("rules = %v, files = %+v; want 13 missing columns and 1 unchecked row", report.Rules, report.Files)
+	}
+
+	if _, err := NewAppModel(zap.NewNop(), cfg).ParseApps(context.Background()); !errors.Is(err, ErrNoKnownColumns) {
+		t.Errorf("ParseApps = %v; want ErrNoKnownColumns", err)
+	}
+	if _, _, err := decodeRows[App](strings.NewReader(apps), FormatCSV, transferCSV(cfg)); !errors.Is(err, ErrNoKnownColumns) {
+		t.Errorf("decodeRows = %v; want ErrNoKnownColumns", err)
+	}
+}
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// QuarantinedRow is a CSV row skipped when loading a dataset. Position is
+// the number of loaded rows before it, rewrites of the file write Record
+// back there. Rows that are not valid CSV have no Record, Raw holds the
+// lines they span instead. Rows a later load no longer finds are kept as
+// resolved.
+type QuarantinedRow struct {
+	Entity     string     `json:"entity"`
+	File       string     `json:"file"`
+	Line       int        `json:"line"`
+	Position   int        `json:"position"`
+	Reason     string     `json:"reason"`
+	Record     []string   `json:"record,omitempty"`
+	Raw        string     `json:"raw,omitempty"`
+	FoundAt    time.Time  `json:"found_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// endLine is the last line of a row that is not valid CSV
+	endLine int
+}
+
+type QuarantineModel struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewQuarantineModel initializes a new QuarantineModel instance
+func NewQuarantineModel(logger *zap.Logger, config config.AppConfig) *QuarantineModel {
+	return &QuarantineModel{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Global Quarantine Variables. quarantineMutex is taken last, loads hold
+// appMutex or reviewMutex while they replace their rows. quarantineFile is
+// the quarantine file as last read or written, another process such as
+// the import command may replace it.
+var (
+	quarantineRows   []QuarantinedRow
+	quarantineLoaded bool
+	quarantineFile   os.FileInfo
+	quarantineMutex  sync.Mutex
+)
+
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (qm *QuarantineModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, qm.logger)
+}
+
+// ListQuarantine: Returns the quarantined rows of entity in file order.
+// An empty entity lists every row.
+func (qm *QuarantineModel) ListQuarantine(ctx context.Context, entity string) ([]QuarantinedRow, error) {
+	quarantineMutex.Lock()
+	defer quarantineMutex.Unlock()
+
+	if err := loadQuarantine(qm.config.QuarantineFilePath); err != nil {
+		qm.log(ctx).Error("Error loading quarantine", zap.Error(err))
+		return nil, err
+	}
+
+	rows := []QuarantinedRow{}
+	for _, row := range quarantineRows {
+		if entity == "" || row.Entity == entity {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// quarantine: Matches the rows the last load of path skipped with the
+// quarantined rows of entity. Rows found again keep their entry with the
+// new line and position, rows no longer found are marked resolved and new
+// ones are added. Failing to store them is logged, the load goes on with
+// the valid rows.
+func (qm *QuarantineModel) quarantine(ctx context.Context, entity, path string, skipped []QuarantinedRow) {
+	quarantineMutex.Lock()
+	defer quarantineMutex.Unlock()
+
+	if len(skipped) > 0 {
+		qm.log(ctx).Warn("Quarantined invalid rows",
+			zap.String("entity", entity),
+			zap.String("file", path),
+			zap.Int("rows", len(skipped)),
+		)
+	}
+
+	if err := loadQuarantine(qm.config.QuarantineFilePath); err != nil {
+		qm.log(ctx).Error("Error loading quarantine", zap.Error(err))
+		return
+	}
+
+	// Identical records are matched in file order
+	found := map[string][]int{}
+	for i, row := range skipped {
+		key := recordKey(row)
+		found[key] = append(found[key], i)
+	}
+
+	now := time.Now().UTC()
+	changed := false
+	matched := make([]bool, len(skipped))
+	rows := make([]QuarantinedRow, 0, len(quarantineRows)+len(skipped))
+	for _, row := range quarantineRows {
+		if row.Entity == entity && row.ResolvedAt == nil {
+			key := recordKey(row)
+			if indexes := found[key]; len(indexes) > 0 {
+				match := skipped[indexes[0]]
+				found[key] = indexes[1:]
+				matched[indexes[0]] = true
+				if row.Line != match.Line || row.Position != match.Position || row.Reason != match.Reason {
+					row.Line, row.Position, row.Reason = match.Line, match.Position, match.Reason
+					changed = true
+				}
+			} else {
+				resolvedAt := now
+				row.ResolvedAt = &resolvedAt
+				changed = true
+			}
+		}
+		rows = append(rows, row)
+	}
+	for i, row := range skipped {
+		if matched[i] {
+			continue
+		}
+		row.Entity = entity
+		row.File = filepath.Base(path)
+		row.FoundAt = now
+		rows = append(rows, row)
+		changed = true
+	}
+
+	// Nothing to rewrite when the load found the rows already stored
+	if !changed {
+		return
+	}
+	if err := saveQuarantine(qm.config.QuarantineFilePath, rows); err != nil {
+		qm.log(ctx).Error("Error writing quarantine", zap.Error(err))
+	}
+}
+
+// heldRows: Returns the unresolved quarantined rows of entity, which
+// rewrites of its file write back. The caller must hold the lock of entity.
+func (qm *QuarantineModel) heldRows(entity string) ([]QuarantinedRow, error) {
+	quarantineMutex.Lock()
+	defer quarantineMutex.Unlock()
+
+	// Nothing was ever quarantined without a quarantine file
+	if qm.config.QuarantineFilePath == "" {
+		return nil, nil
+	}
+	if err := loadQuarantine(qm.config.QuarantineFilePath); err != nil {
+		return nil, err
+	}
+
+	var held []QuarantinedRow
+	for _, row := range quarantineRows {
+		if row.Entity == entity && row.ResolvedAt == nil {
+			held = append(held, row)
+		}
+	}
+	return held, nil
+}
+
+// moveHeld: Stores the lines and positions held rows of entity took in a
+// rewrite of its file. from holds the line each row had before, which
+// tells the rows of a file apart. The caller must hold the lock of entity.
+func (qm *QuarantineModel) moveHeld(ctx context.Context, entity string, from []int, held []QuarantinedRow) {
+	if len(held) == 0 {
+		return
+	}
+	quarantineMutex.Lock()
+	defer quarantineMutex.Unlock()
+
+	if err := loadQuarantine(qm.config.QuarantineFilePath); err != nil {
+		qm.log(ctx).Error("Error loading quarantine", zap.Error(err))
+		return
+	}
+	moved := map[int]QuarantinedRow{}
+	for i, row := range held {
+		moved[from[i]] = row
+	}
+	rows := make([]QuarantinedRow, len(quarantineRows))
+	for i, row := range quarantineRows {
+		if next, ok := moved[row.Line]; ok && row.Entity == entity && row.ResolvedAt == nil {
+			row.Line, row.Position = next.Line, next.Position
+		}
+		rows[i] = row
+	}
+	if err := saveQuarantine(qm.config.QuarantineFilePath, rows); err != nil {
+		qm.log(ctx).Error("Error writing quarantine", zap.Error(err))
+	}
+}
+
+// placeHeld: Moves held rows, positioned in before, to after: each goes
+// right behind the row that preceded it in before, found in after by key.
+// Rows whose preceding rows are all gone move to the top. held is sorted
+// by its new position.
+func placeHeld[T any](held []QuarantinedRow, before, after []T, key func(T) string) {
+	// Repeated keys are told apart by their occurrence
+	occurrence := make([]int, len(before))
+	seen := map[string]int{}
+	for i, row := range before {
+		k := key(row)
+		occurrence[i] = seen[k]
+		seen[k]++
+	}
+	index := map[string][]int{}
+	for i, row := range after {
+		k := key(row)
+		index[k] = append(index[k], i)
+	}
+
+	for i := range held {
+		position := held[i].Position
+		if len(before) == 0 {
+			// Nothing to anchor to, the rows were never loaded
+			held[i].Position = min(position, len(after))
+			continue
+		}
+		held[i].Position = 0
+		for p := min(position, len(before)) - 1; p >= 0; p-- {
+			if indexes := index[key(before[p])]; len(indexes) > 0 {
+				held[i].Position = indexes[min(occurrence[p], len(indexes)-1)] + 1
+				break
+			}
+		}
+	}
+	sort.SliceStable(held, func(i, j int) bool { return held[i].Position < held[j].Position })
+}
+
+// recordKey: Returns the key identical quarantined rows share
+func recordKey(row QuarantinedRow) string {
+	if row.Record == nil {
+		return "raw\x00" + row.Raw
+	}
+	return strings.Join(row.Record, "\x00")
+}
+
+// readRawLines: Fills in Raw of the skipped rows that are not valid CSV
+// with the lines of the file at path they span, read in the dialect of
+// opts. skipped is in file order.
+func readRawLines(path string, opts csvOptions, skipped []QuarantinedRow) error {
+	next := 0
+	advance := func() {
+		for next < len(skipped) && skipped[next].Record != nil {
+			next++
+		}
+	}
+	if advance(); next == len(skipped) {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(opts.dialect.Decode(file))
+	var lines []string
+	for line := 1; next < len(skipped); line++ {
+		text, err := reader.ReadString('\n')
+		if text == "" && err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("line %d is past the end of %s", skipped[next].Line, filepath.Base(path))
+			}
+			return err
+		}
+		row := &skipped[next]
+		if line < row.Line {
+			continue
+		}
+		lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r"))
+		if line >= row.endLine {
+			row.Raw = strings.Join(lines, "\n")
+			lines = nil
+			next++
+			advance()
+		}
+	}
+	return nil
+}
+
+// loadQuarantine: Reads the quarantine file unless it is unchanged since
+// it was last read or written. The caller must hold quarantineMutex.
+func loadQuarantine(path string) error {
+	if path == "" {
+		return fmt.Errorf("quarantine %w", ErrFilePathNotConfigured)
+	}
+	info, changed, err := utils.FileChanged(path, quarantineFile)
+	if err != nil {
+		return err
+	}
+	if quarantineLoaded && !changed {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var rows []QuarantinedRow
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return err
+		}
+	}
+	quarantineRows = rows
+	quarantineLoaded = true
+	quarantineFile = info
+	return nil
+}
+
+// saveQuarantine: Atomically rewrites the quarantine file and replaces the
+// loaded rows. The caller must hold quarantineMutex.
+func saveQuarantine(path string, rows []QuarantinedRow) error {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+
+	quarantineRows = rows
+	// Unless it can be stat, the file is read again next time
+	quarantineFile, err = os.Stat(path)
+	quarantineLoaded = err == nil
+	return nil
+}
+
+// parseLenient: Streams the CSV file r as T in the dialect of opts,
+// matching columns by name after resolving aliases and skipping the
+// records that cannot be decoded. Rows are decoded and passed to prepare
+// on workers goroutines, see streamCSV. The skipped records are returned
+// with their line, position and reason. An error is only returned when r cannot be read.
+func parseLenient[T any](r io.Reader, opts csvOptions, workers int, prepare func(*T) bool) (rows []T, skipped []QuarantinedRow, err error) {
+	err = streamCSV(r, opts, workers, prepare, func(_ int, row T) {
+		rows = append(rows, row)
+	}, func(line int, record []string, err error) {
+		row := QuarantinedRow{Line: line, Position: len(rows), Reason: err.Error(), Record: record}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.endLine = parseErr.Line
+		}
+		skipped = append(skipped, row)
+	})
+	return rows, skipped, err
+}
@@ -0,0 +1,229 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+const quarantineApps = `App,Category,Rating,Reviews,Size,Installs,Type,Price,Content Rating,Genres,Last Updated,Current Ver,Android Ver
+Maps,TRAVEL,4.3,100,10M,"1,000+",Free,0,Everyone,Travel,"May 2, 2018",1.0,4.1 and up
+Broken,TOOLS,four,12,1M,"1,000+",Free,0,Everyone,Tools,"May 2, 2018",1.0,4.1 and up
+Mail,COMMUNICATION,4.1,200,8M,"1,000+",Free,0,Everyone,Communication,"May 2, 2018",1.0,4.1 and up
+Notes,PRODUCTIVITY,4.5,50,3M,"1,000+",Free,0,Everyone,Productivity,"May 2, 2018",1.0,4.1 and up
+`
+
+const brokenRecord = `Broken,TOOLS,four,12,1M,"1,000+",Free,0,Everyone,Tools,"May 2, 2018",1.0,4.1 and up`
+
+// resetQuarantine drops the rows cached by earlier tests
+func resetQuarantine() {
+	quarantineMutex.Lock()
+	quarantineRows, quarantineLoaded, quarantineFile = nil, false, nil
+	quarantineMutex.Unlock()
+	appMutex.Lock()
+	appCache, appFile = nil, nil
+	appMutex.Unlock()
+}
+
+// fileLines returns the lines of the file at path
+func fileLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestRewritesKeepQuarantinedRowsInPlace(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+		TrashFilePath:      filepath.Join(dir, "trash.json"),
+	}
+	if err := os.WriteFile(cfg.CSVFilePath, []byte(quarantineApps), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	qm := NewQuarantineModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	// held checks the broken row is on line and stored as such
+	held := func(step string, line int) {
+		t.Helper()
+		if got := fileLines(t, cfg.CSVFilePath)[line-1]; got != brokenRecord {
+			t.Fatalf("%s: line %d = %q; want the quarantined row", step, line, got)
+		}
+		rows, err := qm.ListQuarantine(ctx, EntityApp)
+		if err != nil || len(rows) != 1 || rows[0].Line != line || rows[0].ResolvedAt != nil {
+			t.Fatalf("%s: quarantine = %+v, %v; want the row on line %d", step, rows, err, line)
+		}
+	}
+
+	if _, err := am.GetAppsFromCache(ctx); err != nil {
+		t.Fatal(err)
+	}
+	held("load", 3)
+
+	if err := am.DeleteApp(ctx, "Maps"); err != nil {
+		t.Fatal(err)
+	}
+	held("delete", 2)
+
+	if _, err := am.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+	held("compact", 2)
+
+	imported := `App,Category,Rating,Reviews,Size,Installs,Type,Price,Content Rating,Genres,Last Updated,Current Ver,Android Ver
+Zoom,BUSINESS,4.0,300,20M,"1,000+",Free,0,Everyone,Business,"May 2, 2018",1.0,4.1 and up
+`
+	if _, err := am.Import(ctx, strings.NewReader(imported), FormatCSV, false); err != nil {
+		t.Fatal(err)
+	}
+	held("import", 2)
+	if lines := fileLines(t, cfg.CSVFilePath); len(lines) != 5 || !strings.HasPrefix(lines[4], "Zoom,") {
+		t.Fatalf("file after import = %q; want Zoom appended", lines)
+	}
+
+	// Fixing the row by hand resolves it, it is kept but not written back
+	lines := fileLines(t, cfg.CSVFilePath)
+	fixed := append(append([]string{}, lines[0]), lines[2:]...)
+	if err := os.WriteFile(cfg.CSVFilePath, []byte(strings.Join(fixed, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := qm.ListQuarantine(ctx, EntityApp)
+	if err != nil || len(rows) != 1 || rows[0].ResolvedAt == nil {
+		t.Fatalf("quarantine = %+v, %v; want the row kept as resolved", rows, err)
+	}
+	if lines := fileLines(t, cfg.CSVFilePath); len(lines) != 4 {
+		t.Fatalf("file after compact = %q; the resolved row must not come back", lines)
+	}
+}
+
+func TestPlaceHeld(t *testing.T) {
+	name := func(app App) string { return app.Name }
+	before := []App{{Name: "A"}, {Name: "B"}, {Name: "A"}, {Name: "C"}}
+
+	tests := []struct {
+		name     string
+		position int
+		after    []App
+		want     int
+	}{
+		{"unchanged", 2, before, 2},
+		{"top", 0, before, 0},
+		{"end", 4, append(before, App{Name: "D"}), 4},
+		{"preceding row deleted", 2, []App{{Name: "A"}, {Name: "C"}}, 1},
+		{"every preceding row deleted", 1, []App{{Name: "C"}}, 0},
+		{"repeated key", 3, []App{{Name: "A"}, {Name: "B"}, {Name: "A"}, {Name: "C"}}, 3},
+		{"rows inserted before", 2, []App{{Name: "Z"}, {Name: "A"}, {Name: "B"}, {Name: "A"}, {Name: "C"}}, 3},
+	}
+	for _, tt := range tests {
+		held := []QuarantinedRow{{Position: tt.position}}
+		placeHeld(held, before, tt.after, name)
+		if held[0].Position != tt.want {
+			t.Errorf("%s: position = %d; want %d", tt.name, held[0].Position, tt.want)
+		}
+	}
+}
+
+func TestQuarantineSeesChangesFromOtherProcesses(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+	qm := NewQuarantineModel(zap.NewNop(), config.AppConfig{QuarantineFilePath: path})
+	ctx := context.Background()
+
+	qm.quarantine(ctx, EntityApp, "apps.csv", []QuarantinedRow{{Line: 3, Reason: "bad rating", Record: []string{"Server"}}})
+
+	// The import command runs in its own process and quarantines a review
+	other := `[{"entity":"review","file":"reviews.csv","line":7,"position":5,"reason":"bad polarity","record":["CLI"],"found_at":"2025-01-02T00:00:00Z"}]`
+	if err := os.WriteFile(path, []byte(other), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := qm.ListQuarantine(ctx, "")
+	if err != nil || len(rows) != 1 || rows[0].Entity != EntityReview {
+		t.Fatalf("ListQuarantine = %+v, %v; want the review quarantined by the other process", rows, err)
+	}
+	qm.quarantine(ctx, EntityApp, "apps.csv", []QuarantinedRow{{Line: 4, Reason: "bad rating", Record: []string{"Again"}}})
+	if rows, err := qm.ListQuarantine(ctx, ""); err != nil || len(rows) != 2 {
+		t.Fatalf("ListQuarantine = %+v, %v; the review must survive the next load", rows, err)
+	}
+}
+
+func TestRewritesKeepRowsThatAreNotValidCSV(t *testing.T) {
+	resetQuarantine()
+	t.Cleanup(resetQuarantine)
+
+	// A bare quote in an unquoted field fails to parse, it has no record
+	badQuote := `Bad "Quote,TOOLS,4.0,12,1M,"1,000+",Free,0,Everyone,Tools,"May 2, 2018",1.0,4.1 and up`
+	apps := strings.Replace(quarantineApps, brokenRecord, badQuote, 1)
+
+	dir := t.TempDir()
+	cfg := config.AppConfig{
+		CSVFilePath:        filepath.Join(dir, "apps.csv"),
+		QuarantineFilePath: filepath.Join(dir, "quarantine.json"),
+		TrashFilePath:      filepath.Join(dir, "trash.json"),
+	}
+	if err := os.WriteFile(cfg.CSVFilePath, []byte(apps), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	am := NewAppModel(zap.NewNop(), cfg)
+	qm := NewQuarantineModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	if _, err := am.GetAppsFromCache(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := qm.ListQuarantine(ctx, EntityApp)
+	if err != nil || len(rows) != 1 || rows[0].Record != nil || rows[0].Raw != badQuote {
+		t.Fatalf("quarantine = %+v, %v; want the raw line of the bad quote", rows, err)
+	}
+
+	if err := am.DeleteApp(ctx, "Maps"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileLines(t, cfg.CSVFilePath)[1]; got != badQuote {
+		t.Fatalf("line 2 = %q; want the bad quote written back", got)
+	}
+	if _, err := am.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = qm.ListQuarantine(ctx, EntityApp)
+	if err != nil || len(rows) != 1 || rows[0].Line != 2 || rows[0].ResolvedAt != nil {
+		t.Fatalf("quarantine = %+v, %v; want the row still quarantined on line 2", rows, err)
+	}
+
+	// Rows stored without their lines block rewrites instead of being lost
+	quarantineMutex.Lock()
+	rows[0].Raw = ""
+	err = saveQuarantine(cfg.QuarantineFilePath, rows)
+	quarantineMutex.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(cfg.CSVFilePath)
+	if err := am.DeleteApp(ctx, "Mail"); !errors.Is(err, ErrQuarantineUnrecoverable) {
+		t.Fatalf("DeleteApp = %v; want ErrQuarantineUnrecoverable", err)
+	}
+	if after, _ := os.ReadFile(cfg.CSVFilePath); string(after) != string(before) {
+		t.Error("the refused rewrite changed the file")
+	}
+}
@@ -1,113 +1,208 @@
 package models
 
 import (
-	"bytes"
-	"encoding/csv"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
-	"github.com/gofiber/fiber/v2"
-	"github.com/jszwec/csvutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 type Review struct {
-	App                   string  `csv:"App" validate:"required"`                       // Required field
-	TranslatedReview      string  `csv:"Translated_Review" validate:"required"`         // Required field
-	Sentiment             string  `csv:"Sentiment" validate:"required"`                 // Required field
-	SentimentPolarity     float64 `csv:"Sentiment_Polarity" validate:"gte=-1,lte=1"`    // Must be between -1 and 1
-	SentimentSubjectivity float64 `csv:"Sentiment_Subjectivity" validate:"gte=0,lte=1"` // Must be between 0 and 1
+	App                   string   `csv:"App" validate:"required"`                       // Required field
+	TranslatedReview      string   `csv:"Translated_Review" validate:"required"`         // Required field
+	Sentiment             string   `csv:"Sentiment" validate:"required"`                 // Required field
+	SentimentPolarity     float64  `csv:"Sentiment_Polarity" validate:"gte=-1,lte=1"`    // Must be between -1 and 1
+	SentimentSubjectivity float64  `csv:"Sentiment_Subjectivity" validate:"gte=0,lte=1"` // Must be between 0 and 1
+	Metadata              Metadata `csv:"-" json:"metadata,omitempty"`                   // Columns of the CSV file without a field
 }
 
 type ReviewModel struct {
+	logger *zap.Logger
 	config config.AppConfig
 }
 
 // NewReviewModel initializes a new ReviewModel instance
 // models/review.go
-func NewReviewModel(config config.AppConfig) *ReviewModel {
+func NewReviewModel(logger *zap.Logger, config config.AppConfig) *ReviewModel {
 	return &ReviewModel{
+		logger: logger,
 		config: config,
 	}
 }
 
-// Global Cache Variables
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (rm *ReviewModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, rm.logger)
+}
+
+// Global Cache Variables. reviewFile is the reviews CSV file as last read or
+// written, another process such as the import command may change it.
 var (
 	reviewCache []Review
+	reviewFile  os.FileInfo
 	reviewMutex sync.RWMutex
-	reviewOnce  sync.Once
 )
 
-// loadCache: Loads review data into cache
-func (rm *ReviewModel) loadCache() error {
-	reviews, err := rm.ParseReviews()
+// loadCache: Loads review data into cache. The caller must hold the
+// write lock of reviewMutex.
+func (rm *ReviewModel) loadCache(ctx context.Context) error {
+	// Changes made while parsing are seen by the next read
+	info := statFile(rm.config.ReviewFilePath)
+	reviews, err := rm.ParseReviews(ctx)
 	if err != nil {
 		return err
 	}
 	reviewCache = reviews
+	reviewFile = info
 	return nil
 }
 
 // ListReviewsFromCache: Returns data from cache or loads it if expired
-func (rm *ReviewModel) ListReviewsFromCache() ([]Review, error) {
-	// First-time cache load
-	reviewOnce.Do(func() {
-		_ = rm.loadCache()
-	})
+func (rm *ReviewModel) ListReviewsFromCache(ctx context.Context) ([]Review, error) {
 	reviewMutex.RLock()
-	defer reviewMutex.RUnlock()
-	if len(reviewCache) == 0 {
-		err := rm.loadCache()
-		if err != nil {
+	if !rm.cacheStale() {
+		defer reviewMutex.RUnlock()
+		return reviewCache, nil
+	}
+	reviewMutex.RUnlock()
+
+	// Loads write the cache, another request may have loaded it meanwhile
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+	return rm.cachedReviews(ctx)
+}
+
+// cacheStale: Reports whether the cache was never loaded or the CSV file
+// changed since it was last read or written. The caller must hold reviewMutex.
+func (rm *ReviewModel) cacheStale() bool {
+	_, changed, err := utils.FileChanged(rm.config.ReviewFilePath, reviewFile)
+	return reviewFile == nil || changed || err != nil
+}
+
+// cachedReviews: Returns the cached reviews, loading them when the cache
+// was never loaded or the CSV file changed. The caller must hold the write
+// lock of reviewMutex.
+func (rm *ReviewModel) cachedReviews(ctx context.Context) ([]Review, error) {
+	if rm.cacheStale() {
+		if err := rm.loadCache(ctx); err != nil {
 			return nil, err
 		}
 	}
 	return reviewCache, nil
 }
 
-// ParseReviews: Reads and parses reviews from CSV using csvutils.Unmarshal
-func (rm *ReviewModel) ParseReviews() ([]Review, error) {
+// Reload: Reads the reviews CSV file again whether or not the cache is
+// filled and reports the reload to the mutation observers. Returns the
+// number of rows loaded.
+func (rm *ReviewModel) Reload(ctx context.Context) (int, error) {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	if err := rm.loadCache(ctx); err != nil {
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionReload,
+		Entity: EntityDataset,
+		Key:    EntityReview,
+		After:  map[string]int{"rows": len(reviewCache)},
+	})
+	return len(reviewCache), nil
+}
+
+// ParseReviews: Streams and parses reviews from CSV using csvutil,
+// decoding and filtering rows in parallel. Rows that do not decode are
+// quarantined and the valid ones are returned.
+func (rm *ReviewModel) ParseReviews(ctx context.Context) (validReviews []Review, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.ParseReviews",
+		trace.WithAttributes(attribute.String("csv.path", rm.config.ReviewFilePath)))
+	defer func() { tracing.End(span, err) }()
+
 	if rm.config.ReviewFilePath == "" {
-		return nil, errors.New("REview file path is not configured")
+		return nil, fmt.Errorf("review %w", ErrFilePathNotConfigured)
 	}
-	records, err := utils.ReadCSV(rm.config.ReviewFilePath)
+
+	file, err := os.Open(rm.config.ReviewFilePath)
 	if err != nil {
 		return nil, errors.New("could not read CSV file")
 	}
-	// Unmarshal CSV into struct
-	var reviews []Review
-	if err := csvutil.Unmarshal(records, &reviews); err != nil {
+	defer file.Close()
+
+	// Unmarshal CSV into struct, filtering out invalid reviews.
+	// Rows that do not decode are quarantined instead of failing the load
+	_, decodeSpan := tracing.Start(ctx, "csv.unmarshal")
+	validReviews, skipped, err := parseLenient(file, reviewsCSV(rm.config), rm.config.CSVParseWorkers, func(review *Review) bool {
+		return loadableReview(*review)
+	})
+	decodeSpan.SetAttributes(
+		attribute.Int("csv.rows", len(validReviews)),
+		attribute.Int("csv.quarantined", len(skipped)),
+	)
+	tracing.End(decodeSpan, err)
+	if err != nil {
 		return nil, err
 	}
-	// Filter out invalid reviews
-	var validReviews []Review
-	for _, review := range reviews {
-		if review.App != "" && review.App != "nan" &&
-			review.Sentiment != "nan" &&
-			!isNaN(review.SentimentPolarity) {
-			validReviews = append(validReviews, review)
-		}
+	// Without its lines a row that is not valid CSV blocks rewrites
+	if err := readRawLines(rm.config.ReviewFilePath, reviewsCSV(rm.config), skipped); err != nil {
+		rm.log(ctx).Warn("Error reading the lines of quarantined rows", zap.Error(err))
 	}
+	NewQuarantineModel(rm.logger, rm.config).quarantine(ctx, EntityReview, rm.config.ReviewFilePath, skipped)
 	return validReviews, nil
 }
 
+// loadableReview: Reports whether review is kept when loading, the dataset
+// marks reviews without text or sentiment with "nan"
+func loadableReview(review Review) bool {
+	return review.App != "" && review.App != "nan" &&
+		review.Sentiment != "nan" &&
+		!isNaN(review.SentimentPolarity)
+}
+
 // Helper function to check if float is NaN
 func isNaN(f float64) bool {
 	return f != f
 }
 
-// ListReviews: Fetches reviews based on filters
-func (rm *ReviewModel) ListReviews(c *fiber.Ctx, appName, sentiment string, polarityMin, polarityMax float64) ([]Review, error) {
-	reviews, err := rm.ListReviewsFromCache()
+// ListReviews: Fetches reviews based on filters.
+// A non-zero asOf answers from the dataset as it was at that time.
+func (rm *ReviewModel) ListReviews(ctx context.Context, appName, sentiment string, polarityMin, polarityMax float64, asOf time.Time) (filteredReviews []Review, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.ListReviews", trace.WithAttributes(
+		attribute.String("filter.app", appName),
+		attribute.String("filter.sentiment", sentiment),
+		attribute.Float64("filter.polarity_min", polarityMin),
+		attribute.Float64("filter.polarity_max", polarityMax),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("result.count", len(filteredReviews)))
+		tracing.End(span, err)
+	}()
+
+	reviews, err := rm.ListReviewsFromCache(ctx)
 	if err != nil {
+		rm.log(ctx).Error(constants.ErrParsingReviewsCSV, zap.Error(err))
 		return nil, err
 	}
-	var filteredReviews []Review
+	if !asOf.IsZero() {
+		span.SetAttributes(attribute.String("as_of", asOf.Format(time.RFC3339)))
+		reviews, err = NewRevisionModel(rm.logger, rm.config).reviewsAsOf(reviews, asOf)
+		if err != nil {
+			rm.log(ctx).Error("Error rebuilding reviews from revisions", zap.Error(err))
+			return nil, err
+		}
+	}
 	for _, review := range reviews {
 		matchesApp := appName == "" ||
 			strings.EqualFold(strings.TrimSpace(review.App), strings.TrimSpace(appName))
@@ -125,96 +220,227 @@ func (rm *ReviewModel) ListReviews(c *fiber.Ctx, appName, sentiment string, pola
 
 	if len(filteredReviews) == 0 {
 		return nil, fmt.Errorf(
-			"No reviews found matching: App=%s, Sentiment=%s, Polarity=%f-%f",
-			appName, sentiment, polarityMin, polarityMax,
+			"%w matching: App=%s, Sentiment=%s, Polarity=%f-%f",
+			ErrReviewsNotFound, appName, sentiment, polarityMin, polarityMax,
 		)
 	}
 	return filteredReviews, nil
 }
-func (rm *ReviewModel) AddReview(review Review) error {
+
+// AddReview: Appends a review to the CSV file and the cache.
+// Identical reviews are handled according to the duplicate policy.
+func (rm *ReviewModel) AddReview(ctx context.Context, review Review) (result InsertResult, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.AddReview",
+		trace.WithAttributes(attribute.String("app.name", review.App)))
+	defer func() {
+		span.SetAttributes(attribute.String("insert.result", string(result)))
+		tracing.End(span, err)
+	}()
+
+	done, err := beginWrite()
+	if err != nil {
+		return "", err
+	}
+	defer done()
 
 	reviewMutex.Lock()
 	defer reviewMutex.Unlock()
 
-	file, err := os.OpenFile(rm.config.ReviewFilePath, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	reviews, err := rm.cachedReviews(ctx)
 	if err != nil {
-		return err
+		rm.log(ctx).Error(constants.ErrParsingReviewsCSV, zap.Error(err))
+		return "", err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	duplicate, err := rm.applyDuplicatePolicy(reviews, review)
+	if err != nil {
+		return "", err
+	}
+	if duplicate {
+		// The identical review is already stored, nothing to write
+		return InsertMerged, nil
+	}
 
-	record := []string{
-		review.App,
-		review.TranslatedReview,
-		review.Sentiment,
-		fmt.Sprintf("%f", review.SentimentPolarity),
-		fmt.Sprintf("%f", review.SentimentSubjectivity),
+	// Appends follow the file header, a file lacking a column the review
+	// fills is rewritten whole with the column added
+	opts := reviewsCSV(rm.config)
+	schema, err := readCSVSchema[Review](rm.config.ReviewFilePath, opts)
+	if err != nil {
+		rm.log(ctx).Error(constants.ErrReadingReviewsCSVRecords, zap.Error(err))
+		return "", err
+	}
+	if extended := extendSchema(schema, []Review{review}); len(extended.header) > len(schema.header) {
+		all := append(append(make([]Review, 0, len(reviews)+1), reviews...), review)
+		if err := rm.writeReviews(ctx, all); err != nil {
+			return "", err
+		}
+	} else {
+		if err := appendCSV(rm.config.ReviewFilePath, opts.dialect, schema, []Review{review}); err != nil {
+			rm.log(ctx).Error(constants.ErrWritingReviewsCSVRecords, zap.Error(err))
+			return "", err
+		}
+		// Append to the in-memory cache
+		reviewCache = append(reviewCache, review)
+		reviewFile = statFile(rm.config.ReviewFilePath)
 	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionCreate,
+		Entity: EntityReview,
+		Key:    review.App,
+		After:  review,
+	})
+
+	return InsertCreated, nil
+}
+
+// DeleteReview: Moves every review for the given app to the trash and rewrites the CSV
+func (rm *ReviewModel) DeleteReview(ctx context.Context, appName string) (err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.DeleteReview",
+		trace.WithAttributes(attribute.String("app.name", appName)))
+	defer func() { tracing.End(span, err) }()
 
-	if err := writer.Write(record); err != nil {
+	done, err := beginWrite()
+	if err != nil {
 		return err
 	}
+	defer done()
 
-	// Append to the in-memory cache
-	reviewCache = append(reviewCache, review)
-
-	return nil
-}
-func (rm *ReviewModel) DeleteReview(appName string) error {
 	reviewMutex.Lock()
 	defer reviewMutex.Unlock()
 
-	// 1. Read all reviews from CSV
-	reviews, err := rm.ParseReviews()
+	// 1. Read all reviews from the cache, loading the CSV when it changed
+	reviews, err := rm.cachedReviews(ctx)
 	if err != nil {
+		rm.log(ctx).Error(constants.ErrParsingReviewsCSV, zap.Error(err))
 		return errors.New(constants.ErrParsingReviewsCSV) // Use constant here
 	}
 
 	// 2. Filter out the reviews with matching app name
-	var updatedReviews []Review
-	found := false
+	var updatedReviews, deletedReviews []Review
 	for _, review := range reviews {
 		if !strings.EqualFold(strings.TrimSpace(review.App), strings.TrimSpace(appName)) {
 			updatedReviews = append(updatedReviews, review)
 		} else {
-			found = true
+			deletedReviews = append(deletedReviews, review)
 		}
 	}
 
-	if !found {
-		return errors.New(constants.AppNotFoundErrorMessage) // Use constant here
+	if len(deletedReviews) == 0 {
+		return fmt.Errorf("%w for app %s", ErrReviewsNotFound, appName)
 	}
 
-	// 3. Rewrite the CSV file
-	file, err := os.Create(rm.config.ReviewFilePath)
+	// 3. Keep the deleted reviews in the trash so they can be restored
+	trashID, err := moveToTrash(ctx, rm.config.TrashFilePath, TrashEntry{
+		Kind:    TrashKindReview,
+		Name:    strings.TrimSpace(appName),
+		Reviews: deletedReviews,
+	})
 	if err != nil {
-		return errors.New(constants.ErrCreatingReviewsCSVFile) // Use constant here
+		rm.log(ctx).Error("Error moving reviews to trash", zap.Error(err))
+		return err
+	}
+
+	// 4. Rewrite the CSV file and update the in-memory cache
+	if err := rm.writeReviews(ctx, updatedReviews); err != nil {
+		if dropErr := dropFromTrash(rm.config.TrashFilePath, trashID); dropErr != nil {
+			rm.log(ctx).Error("Error removing trash entry of failed delete", zap.Error(dropErr))
+		}
+		return err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionDelete,
+		Entity: EntityReview,
+		Key:    appName,
+		Before: deletedReviews,
+	})
+	return nil
+}
+
+// RestoreReviews: Puts back the reviews removed by the most recent delete for appName
+func (rm *ReviewModel) RestoreReviews(ctx context.Context, appName string) (restored int, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewModel.RestoreReviews",
+		trace.WithAttributes(attribute.String("app.name", appName)))
+	defer func() { tracing.End(span, err) }()
+
+	done, err := beginWrite()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	reviews, err := rm.cachedReviews(ctx)
+	if err != nil {
+		rm.log(ctx).Error(constants.ErrParsingReviewsCSV, zap.Error(err))
+		return 0, err
+	}
+
+	entry, err := takeFromTrash(rm.config.TrashFilePath, TrashKindReview, strings.TrimSpace(appName))
+	if err != nil {
+		return 0, err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	restoredReviews := append(append(make([]Review, 0, len(reviews)+len(entry.Reviews)), reviews...), entry.Reviews...)
+	if err := rm.writeReviews(ctx, restoredReviews); err != nil {
+		if returnErr := returnToTrash(rm.config.TrashFilePath, entry); returnErr != nil {
+			rm.log(ctx).Error("Error returning entry to trash", zap.Error(returnErr))
+		}
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionRestore,
+		Entity: EntityReview,
+		Key:    entry.Name,
+		After:  entry.Reviews,
+	})
+	return len(entry.Reviews), nil
+}
 
-	csvBytes, err := csvutil.Marshal(updatedReviews)
+// writeReviews: Atomically rewrites the reviews CSV file and replaces the cache.
+// Columns keep the names and order of the file header; fields and metadata
+// columns it lacks are appended. Quarantined rows keep their place among
+// the cached rows. The caller must hold reviewMutex.
+func (rm *ReviewModel) writeReviews(ctx context.Context, reviews []Review) error {
+	opts := reviewsCSV(rm.config)
+	schema, err := readCSVSchema[Review](rm.config.ReviewFilePath, opts)
 	if err != nil {
-		return errors.New(constants.ErrMarshallingReviewsCSVData) // Use constant here
+		rm.log(ctx).Error(constants.ErrReadingReviewsCSVRecords, zap.Error(err))
+		return errors.New(constants.ErrReadingReviewsCSVRecords) // Use constant here
 	}
 
-	r := csv.NewReader(bytes.NewReader(csvBytes))
-	records, err := r.ReadAll()
+	// Quarantined rows are written back behind the rows that preceded them
+	quarantine := NewQuarantineModel(rm.logger, rm.config)
+	held, err := quarantine.heldRows(EntityReview)
 	if err != nil {
+		rm.log(ctx).Error("Error loading quarantine", zap.Error(err))
 		return errors.New(constants.ErrReadingReviewsCSVRecords) // Use constant here
 	}
+	placeHeld(held, reviewCache, reviews, reviewKey)
+	from := make([]int, len(held))
+	for i := range held {
+		from[i] = held[i].Line
+	}
 
-	err = writer.WriteAll(records)
+	// Rows are encoded straight into the temporary file, which replaces the
+	// CSV file in one rename so a crash never leaves it truncated
+	err = utils.WriteFileAtomicFunc(rm.config.ReviewFilePath, 0o644, func(w io.Writer) error {
+		return writeCSV(w, opts.dialect, extendSchema(schema, reviews), reviews, held)
+	})
 	if err != nil {
-		return errors.New(constants.ErrWritingReviewsCSVRecords) // Use constant here
+		rm.log(ctx).Error(constants.ErrCreatingReviewsCSVFile, zap.Error(err))
+		if errors.Is(err, ErrQuarantineUnrecoverable) {
+			return err
+		}
+		return errors.New(constants.ErrCreatingReviewsCSVFile) // Use constant here
 	}
 
-	// 4. Update the in-memory cache
-	reviewCache = updatedReviews
+	quarantine.moveHeld(ctx, EntityReview, from, held)
+
+	// Update the in-memory cache
+	reviewCache = reviews
+	reviewFile = statFile(rm.config.ReviewFilePath)
 
 	return nil
 }
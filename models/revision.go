@@ -0,0 +1,344 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Revision is a versioned copy of the rows one mutation changed
+type Revision struct {
+	Version       int       `json:"version"`
+	Time          time.Time `json:"time"`
+	Actor         string    `json:"actor"`
+	RequestID     string    `json:"request_id,omitempty"`
+	Action        string    `json:"action"`
+	Entity        string    `json:"entity"`
+	Key           string    `json:"key,omitempty"`
+	BeforeApps    []App     `json:"before_apps,omitempty"`
+	AfterApps     []App     `json:"after_apps,omitempty"`
+	BeforeReviews []Review  `json:"before_reviews,omitempty"`
+	AfterReviews  []Review  `json:"after_reviews,omitempty"`
+}
+
+// FieldChange is one field that differs between two versions of a row
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// AppHistoryEntry is a revision of an app with the fields it changed
+type AppHistoryEntry struct {
+	Revision
+	Changes []FieldChange `json:"changes"`
+}
+
+type RevisionModel struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewRevisionModel initializes a new RevisionModel instance
+func NewRevisionModel(logger *zap.Logger, config config.AppConfig) *RevisionModel {
+	return &RevisionModel{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Global Revision Variables. revisionFile is the revision file as last
+// read or appended to, the import and purge commands append to it from
+// their own processes.
+var (
+	revisions       []Revision
+	revisionsLoaded bool
+	revisionFile    os.FileInfo
+	revisionMutex   sync.RWMutex
+)
+
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (vm *RevisionModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, vm.logger)
+}
+
+// Record: Stores a mutation of apps or reviews as a new revision.
+// It is registered with OnMutation.
+func (vm *RevisionModel) Record(ctx context.Context, mutation Mutation) {
+	if mutation.Entity != EntityApp && mutation.Entity != EntityReview {
+		return
+	}
+
+	revision := Revision{
+		Time:          mutation.Time,
+		Actor:         mutation.Actor,
+		RequestID:     mutation.RequestID,
+		Action:        mutation.Action,
+		Entity:        mutation.Entity,
+		Key:           mutation.Key,
+		BeforeApps:    appRows(mutation.Before),
+		AfterApps:     appRows(mutation.After),
+		BeforeReviews: reviewRows(mutation.Before),
+		AfterReviews:  reviewRows(mutation.After),
+	}
+
+	revisionMutex.Lock()
+	defer revisionMutex.Unlock()
+
+	if err := vm.loadRevisions(); err != nil {
+		vm.log(ctx).Error("Error loading revisions", zap.Error(err))
+		return
+	}
+	revision.Version = len(revisions) + 1
+
+	if err := vm.appendRevision(revision); err != nil {
+		vm.log(ctx).Error("Error writing revision", zap.Error(err))
+		return
+	}
+	revisions = append(revisions, revision)
+}
+
+// AppHistory: Returns every revision that touched an app named appName,
+// newest first, with the fields each one changed
+func (vm *RevisionModel) AppHistory(ctx context.Context, appName string) ([]AppHistoryEntry, error) {
+	revisionMutex.Lock()
+	defer revisionMutex.Unlock()
+
+	if err := vm.loadRevisions(); err != nil {
+		vm.log(ctx).Error("Error loading revisions", zap.Error(err))
+		return nil, err
+	}
+
+	history := []AppHistoryEntry{}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		before := appsNamed(revision.BeforeApps, appName)
+		after := appsNamed(revision.AfterApps, appName)
+		if revision.Entity != EntityApp || len(before)+len(after) == 0 {
+			continue
+		}
+
+		var from, to App
+		if len(before) > 0 {
+			from = before[0]
+		}
+		if len(after) > 0 {
+			to = after[0]
+		}
+		history = append(history, AppHistoryEntry{
+			Revision: revision,
+			Changes:  diffFields(from, to),
+		})
+	}
+
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: no history for %s", ErrAppNotFound, appName)
+	}
+	return history, nil
+}
+
+// appsAsOf: Rebuilds the apps as they were at t by undoing, newest first,
+// every revision recorded after t
+func (vm *RevisionModel) appsAsOf(current []App, t time.Time) ([]App, error) {
+	revisionMutex.Lock()
+	defer revisionMutex.Unlock()
+
+	if err := vm.loadRevisions(); err != nil {
+		return nil, err
+	}
+
+	apps := append([]App{}, current...)
+	for i := len(revisions) - 1; i >= 0 && revisions[i].Time.After(t); i-- {
+		if revisions[i].Entity != EntityApp {
+			continue
+		}
+		apps = append(removeRows(apps, revisions[i].AfterApps), revisions[i].BeforeApps...)
+	}
+	return apps, nil
+}
+
+// reviewsAsOf: Rebuilds the reviews as they were at t
+func (vm *RevisionModel) reviewsAsOf(current []Review, t time.Time) ([]Review, error) {
+	revisionMutex.Lock()
+	defer revisionMutex.Unlock()
+
+	if err := vm.loadRevisions(); err != nil {
+		return nil, err
+	}
+
+	reviews := append([]Review{}, current...)
+	for i := len(revisions) - 1; i >= 0 && revisions[i].Time.After(t); i-- {
+		if revisions[i].Entity != EntityReview {
+			continue
+		}
+		reviews = append(removeRows(reviews, revisions[i].AfterReviews), revisions[i].BeforeReviews...)
+	}
+	return reviews, nil
+}
+
+// removeRows: Removes one occurrence of every row in remove from rows
+func removeRows[T comparable](rows, remove []T) []T {
+	pending := map[T]int{}
+	for _, row := range remove {
+		pending[row]++
+	}
+
+	kept := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if pending[row] > 0 {
+			pending[row]--
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept
+}
+
+// appsNamed: Returns the apps called name
+func appsNamed(apps []App, name string) []App {
+	var named []App
+	for _, app := range apps {
+		if app.Name == name {
+			named = append(named, app)
+		}
+	}
+	return named
+}
+
+// appRows: Returns the apps carried by a mutation snapshot
+func appRows(snapshot interface{}) []App {
+	switch rows := snapshot.(type) {
+	case App:
+		return []App{rows}
+	case []App:
+		return rows
+	}
+	return nil
+}
+
+// reviewRows: Returns the reviews carried by a mutation snapshot
+func reviewRows(snapshot interface{}) []Review {
+	switch rows := snapshot.(type) {
+	case Review:
+		return []Review{rows}
+	case []Review:
+		return rows
+	}
+	return nil
+}
+
+// diffFields: Lists the fields that differ between two structs of the same type
+func diffFields(from, to interface{}) []FieldChange {
+	changes := []FieldChange{}
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		a, b := fromValue.Field(i).Interface(), toValue.Field(i).Interface()
+		if a == b {
+			continue
+		}
+		change := FieldChange{Field: fromValue.Type().Field(i).Tag.Get("csv")}
+		if change.Field == "-" {
+			// Metadata has no CSV column of its own
+			change.Field = strings.SplitN(fromValue.Type().Field(i).Tag.Get("json"), ",", 2)[0]
+		}
+		if !fromValue.Field(i).IsZero() {
+			change.From = a
+		}
+		if !toValue.Field(i).IsZero() {
+			change.To = b
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// loadRevisions: Reads the revision file once.
+// The caller must hold revisionMutex.
+func (vm *RevisionModel) loadRevisions() error {
+	if vm.config.RevisionFilePath == "" {
+		return fmt.Errorf("revision %w", ErrFilePathNotConfigured)
+	}
+	info, changed, err := utils.FileChanged(vm.config.RevisionFilePath, revisionFile)
+	if err != nil {
+		return err
+	}
+	if revisionsLoaded && !changed {
+		return nil
+	}
+
+	file, err := os.Open(vm.config.RevisionFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		revisions = nil
+		revisionsLoaded = true
+		revisionFile = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var loaded []Revision
+	scanner := bufio.NewScanner(file)
+	// Revisions of large deletes make for long lines
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var revision Revision
+		if err := json.Unmarshal(scanner.Bytes(), &revision); err != nil {
+			return err
+		}
+		loaded = append(loaded, revision)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	revisions = loaded
+	revisionsLoaded = true
+	revisionFile = info
+	return nil
+}
+
+// appendRevision: Appends revision to the revision file.
+// The caller must hold revisionMutex.
+func (vm *RevisionModel) appendRevision(revision Revision) error {
+	line, err := json.Marshal(revision)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(vm.config.RevisionFilePath), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(vm.config.RevisionFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	// Unless it can be stat, the file is read again next time
+	revisionFile, err = file.Stat()
+	revisionsLoaded = revisionsLoaded && err == nil
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestRevisionsSeeAppendsFromOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revisions.jsonl")
+	server := NewRevisionModel(zap.NewNop(), config.AppConfig{RevisionFilePath: path})
+	ctx := context.Background()
+
+	server.Record(ctx, Mutation{Time: time.Now(), Action: ActionCreate, Entity: EntityApp, Key: "Server", After: App{Name: "Server"}})
+
+	// The import command runs in its own process and appends to the file
+	line, err := json.Marshal(Revision{Version: 2, Time: time.Now(), Actor: "cli", Action: ActionImport, Entity: EntityApp, AfterApps: []App{{Name: "Imported"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	history, err := server.AppHistory(ctx, "Imported")
+	if err != nil || len(history) != 1 || history[0].Actor != "cli" {
+		t.Fatalf("AppHistory(Imported) = %+v, %v; want the imported revision", history, err)
+	}
+
+	server.Record(ctx, Mutation{Time: time.Now(), Action: ActionDelete, Entity: EntityApp, Key: "Server", Before: App{Name: "Server"}})
+	history, err = server.AppHistory(ctx, "Server")
+	if err != nil || len(history) != 2 || history[0].Version != 3 {
+		t.Fatalf("AppHistory(Server) = %+v, %v; want the delete recorded as version 3", history, err)
+	}
+}
@@ -0,0 +1,397 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jszwec/csvutil"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/csvdialect"
+)
+
+// Metadata holds the values of CSV columns a row has no field for, by
+// column name, so they survive rewrites of the file. It is kept as
+// canonical JSON so rows stay comparable.
+type Metadata string
+
+// NewMetadata: Returns the metadata holding values
+func NewMetadata(values map[string]string) Metadata {
+	if len(values) == 0 {
+		return ""
+	}
+	// Map keys are marshalled sorted, equal values give equal metadata
+	data, _ := json.Marshal(values)
+	return Metadata(data)
+}
+
+// Map: Returns the values by column name
+func (m Metadata) Map() map[string]string {
+	values := map[string]string{}
+	if m != "" {
+		_ = json.Unmarshal([]byte(m), &values)
+	}
+	return values
+}
+
+// MarshalJSON: Writes the metadata as a JSON object
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	if m == "" {
+		return []byte("null"), nil
+	}
+	return []byte(m), nil
+}
+
+// UnmarshalJSON: Reads the metadata from a JSON object of strings
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*m = NewMetadata(values)
+	return nil
+}
+
+// withMetadata is implemented by rows keeping their extra CSV columns
+type withMetadata interface {
+	metadata() Metadata
+	setMetadata(Metadata)
+}
+
+func (a *App) metadata() Metadata        { return a.Metadata }
+func (a *App) setMetadata(m Metadata)    { a.Metadata = m }
+func (r *Review) metadata() Metadata     { return r.Metadata }
+func (r *Review) setMetadata(m Metadata) { r.Metadata = m }
+
+// csvOptions are the settings a dataset file is read and written with
+type csvOptions struct {
+	dialect csvdialect.Dialect
+	aliases map[string]string
+}
+
+// appsCSV: Returns the settings of the apps file. The dialect was checked
+// when the config loaded, an invalid one falls back to the default.
+func appsCSV(cfg config.AppConfig) csvOptions {
+	dialect, _ := cfg.AppsDialect()
+	return csvOptions{dialect: dialect, aliases: cfg.CSVHeaderAliases}
+}
+
+// reviewsCSV: Returns the settings of the reviews file
+func reviewsCSV(cfg config.AppConfig) csvOptions {
+	dialect, _ := cfg.ReviewsDialect()
+	return csvOptions{dialect: dialect, aliases: cfg.CSVHeaderAliases}
+}
+
+// transferCSV: Returns the settings of imported and exported files, plain
+// comma separated UTF-8 whatever the dialect of the datasets
+func transferCSV(cfg config.AppConfig) csvOptions {
+	return csvOptions{dialect: csvdialect.Default, aliases: cfg.CSVHeaderAliases}
+}
+
+// csvSchema maps the columns of a CSV file to the fields of a row type.
+// Columns are matched by name after resolving aliases, so files may order
+// them freely; columns without a field are kept as metadata.
+type csvSchema struct {
+	// header holds the column names as written in the file
+	header []string
+	// fields holds the csv tag of the field each column fills, or "" for
+	// metadata columns
+	fields []string
+}
+
+// newCSVSchema: Maps header to the csv tags of T. aliases rename file
+// columns to tags; a second column for the same field is kept as metadata.
+func newCSVSchema[T any](header []string, aliases map[string]string) csvSchema {
+	known := map[string]bool{}
+	tags, _ := csvutil.Header(*new(T), "csv")
+	for _, tag := range tags {
+		known[tag] = true
+	}
+
+	schema := csvSchema{header: header, fields: make([]string, len(header))}
+	used := map[string]bool{}
+	for i, column := range header {
+		name := strings.TrimSpace(column)
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if known[name] && !used[name] {
+			schema.fields[i] = name
+			used[name] = true
+		}
+	}
+	return schema
+}
+
+// readCSVSchema: Returns the schema of the CSV file at path, or the
+// schema of T's own header when the file is missing or empty
+func readCSVSchema[T any](path string, opts csvOptions) (csvSchema, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newCSVSchema[T](nil, opts.aliases), nil
+	}
+	if err != nil {
+		return csvSchema{}, err
+	}
+	defer file.Close()
+
+	header, err := opts.dialect.NewReader(file).Read()
+	if errors.Is(err, io.EOF) {
+		return newCSVSchema[T](nil, opts.aliases), nil
+	}
+	if err != nil {
+		return csvSchema{}, err
+	}
+	return newCSVSchema[T](header, opts.aliases), nil
+}
+
+// decoderHeader: Returns the tags of the columns filling fields, in file order
+func (s csvSchema) decoderHeader() []string {
+	header := make([]string, 0, len(s.fields))
+	for _, field := range s.fields {
+		if field != "" {
+			header = append(header, field)
+		}
+	}
+	return header
+}
+
+// split: Separates a record into the values of decoderHeader and the
+// metadata columns
+func (s csvSchema) split(record []string) ([]string, Metadata) {
+	values := make([]string, 0, len(record))
+	var extra map[string]string
+	for i, value := range record {
+		if s.fields[i] != "" {
+			values = append(values, value)
+			continue
+		}
+		if extra == nil {
+			extra = map[string]string{}
+		}
+		extra[s.header[i]] = value
+	}
+	return values, NewMetadata(extra)
+}
+
+// missing: Returns the tags of T no column fills
+func (s csvSchema) missing(tags []string) []string {
+	filled := map[string]bool{}
+	for _, field := range s.fields {
+		filled[field] = true
+	}
+	var missing []string
+	for _, tag := range tags {
+		if !filled[tag] {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
+
+// extendSchema: Returns s with the fields of T it lacks and the metadata
+// columns of rows it lacks appended, so writing rows loses nothing. The
+// columns of s keep their names and order.
+func extendSchema[T any](s csvSchema, rows []T) csvSchema {
+	extended := csvSchema{
+		header: append([]string{}, s.header...),
+		fields: append([]string{}, s.fields...),
+	}
+
+	tags, _ := csvutil.Header(*new(T), "csv")
+	for _, tag := range s.missing(tags) {
+		extended.header = append(extended.header, tag)
+		extended.fields = append(extended.fields, tag)
+	}
+
+	present := map[string]bool{}
+	for i, column := range s.header {
+		if s.fields[i] == "" {
+			present[column] = true
+		}
+	}
+	var added []string
+	for i := range rows {
+		row, ok := any(&rows[i]).(withMetadata)
+		if !ok {
+			continue
+		}
+		for column := range row.metadata().Map() {
+			if !present[column] {
+				present[column] = true
+				added = append(added, column)
+			}
+		}
+	}
+	sort.Strings(added)
+	for _, column := range added {
+		extended.header = append(extended.header, column)
+		extended.fields = append(extended.fields, "")
+	}
+	return extended
+}
+
+// rowEncoder encodes rows as records in the column order of a schema.
+// Fields the schema has no column for and metadata of other columns are
+// left out.
+type rowEncoder[T any] struct {
+	schema    csvSchema
+	index     map[string]int
+	collector *recordCollector
+	encoder   *csvutil.Encoder
+}
+
+// newRowEncoder: Returns an encoder of rows of T in the column order of s
+func newRowEncoder[T any](s csvSchema) (*rowEncoder[T], error) {
+	tags, err := csvutil.Header(*new(T), "csv")
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(tags))
+	for i, tag := range tags {
+		index[tag] = i
+	}
+
+	collector := &recordCollector{}
+	encoder := csvutil.NewEncoder(collector)
+	encoder.AutoHeader = false
+	return &rowEncoder[T]{schema: s, index: index, collector: collector, encoder: encoder}, nil
+}
+
+// encode: Returns the record of row
+func (e *rowEncoder[T]) encode(row *T) ([]string, error) {
+	if err := e.encoder.Encode(*row); err != nil {
+		return nil, err
+	}
+	var extra map[string]string
+	if withMeta, ok := any(row).(withMetadata); ok {
+		extra = withMeta.metadata().Map()
+	}
+
+	record := make([]string, len(e.schema.header))
+	for j, field := range e.schema.fields {
+		if field != "" {
+			record[j] = e.collector.record[e.index[field]]
+		} else {
+			record[j] = extra[e.schema.header[j]]
+		}
+	}
+	return record, nil
+}
+
+// writeCSV: Writes the header of s followed by rows in dialect, one record
+// at a time. The raw records of held, sorted by position, are written in
+// between: a held row goes before the row at its position, rows past the
+// end go last, rows that are not valid CSV as their raw lines. The line
+// each held row lands on is stored in its Line. A held row with neither a
+// record nor lines fails the write before anything is written.
+func writeCSV[T any](w io.Writer, dialect csvdialect.Dialect, s csvSchema, rows []T, held []QuarantinedRow) error {
+	for _, row := range held {
+		if row.Record == nil && row.Raw == "" {
+			return fmt.Errorf("%w: line %d of %s", ErrQuarantineUnrecoverable, row.Line, row.File)
+		}
+	}
+	encoder, err := newRowEncoder[T](s)
+	if err != nil {
+		return err
+	}
+	writer := dialect.NewWriter(w)
+	if err := writer.WriteHeader(s.header); err != nil {
+		return err
+	}
+	line := recordLines(s.header)
+
+	next := 0
+	writeHeld := func(position int) error {
+		for ; next < len(held) && held[next].Position <= position; next++ {
+			held[next].Line = line + 1
+			if held[next].Record == nil {
+				if err := writer.WriteRaw(held[next].Raw); err != nil {
+					return err
+				}
+				line += strings.Count(held[next].Raw, "\n") + 1
+				continue
+			}
+			if err := writer.Write(held[next].Record); err != nil {
+				return err
+			}
+			line += recordLines(held[next].Record)
+		}
+		return nil
+	}
+	for i := range rows {
+		if err := writeHeld(i); err != nil {
+			return err
+		}
+		record, err := encoder.encode(&rows[i])
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		line += recordLines(record)
+	}
+	if err := writeHeld(len(held) + len(rows)); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// recordLines: Returns the number of lines record takes in a CSV file,
+// quoted fields may hold line breaks
+func recordLines(record []string) int {
+	lines := 1
+	for _, field := range record {
+		lines += strings.Count(field, "\n")
+	}
+	return lines
+}
+
+// appendCSV: Appends rows to the CSV file at path in the column order of s
+// and dialect, and syncs it
+func appendCSV[T any](path string, dialect csvdialect.Dialect, s csvSchema, rows []T) error {
+	encoder, err := newRowEncoder[T](s)
+	if err != nil {
+		return err
+	}
+	records := make([][]string, 0, len(rows))
+	for i := range rows {
+		record, err := encoder.encode(&rows[i])
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := dialect.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// recordCollector keeps the last record a csvutil.Encoder wrote
+type recordCollector struct {
+	record []string
+}
+
+// Write: Keeps a copy of record
+func (c *recordCollector) Write(record []string) error {
+	c.record = append(c.record[:0], record...)
+	return nil
+}
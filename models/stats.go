@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+)
+
+// DatasetStats are aggregates over the apps and reviews
+type DatasetStats struct {
+	Apps          int            `json:"apps"`
+	Reviews       int            `json:"reviews"`
+	AverageRating float64        `json:"average_rating"`
+	FreeApps      int            `json:"free_apps"`
+	PaidApps      int            `json:"paid_apps"`
+	Categories    map[string]int `json:"categories"`
+	Sentiments    map[string]int `json:"sentiments"`
+	RefreshedAt   time.Time      `json:"refreshed_at"`
+}
+
+type StatsModel struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewStatsModel initializes a new StatsModel instance
+func NewStatsModel(logger *zap.Logger, config config.AppConfig) *StatsModel {
+	return &StatsModel{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Global Stats Variables
+var (
+	statsCache *DatasetStats
+	statsMutex sync.Mutex
+	// statsGeneration counts invalidations so a Refresh that read the
+	// data before one does not cache stale aggregates
+	statsGeneration uint64
+)
+
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (sm *StatsModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, sm.logger)
+}
+
+// Stats: Returns the cached aggregates, computing them on first use
+func (sm *StatsModel) Stats(ctx context.Context) (DatasetStats, error) {
+	statsMutex.Lock()
+	cached := statsCache
+	statsMutex.Unlock()
+
+	if cached != nil {
+		return *cached, nil
+	}
+	return sm.Refresh(ctx)
+}
+
+// Invalidate: Drops the cached aggregates after apps or reviews changed,
+// the next Stats recomputes them. It is registered with OnMutation.
+func (sm *StatsModel) Invalidate(ctx context.Context, mutation Mutation) {
+	if mutation.Entity == EntityTrash {
+		return
+	}
+
+	statsMutex.Lock()
+	statsCache = nil
+	statsGeneration++
+	statsMutex.Unlock()
+}
+
+// Refresh: Recomputes the aggregates from the cached apps and reviews
+func (sm *StatsModel) Refresh(ctx context.Context) (DatasetStats, error) {
+	statsMutex.Lock()
+	generation := statsGeneration
+	statsMutex.Unlock()
+
+	apps, err := NewAppModel(sm.logger, sm.config).GetAppsFromCache(ctx)
+	if err != nil {
+		sm.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return DatasetStats{}, err
+	}
+	reviews, err := NewReviewModel(sm.logger, sm.config).ListReviewsFromCache(ctx)
+	if err != nil {
+		sm.log(ctx).Error(constants.ErrorLoadingCache, zap.Error(err))
+		return DatasetStats{}, err
+	}
+
+	stats := DatasetStats{
+		Apps:        len(apps),
+		Reviews:     len(reviews),
+		Categories:  map[string]int{},
+		Sentiments:  map[string]int{},
+		RefreshedAt: time.Now().UTC(),
+	}
+
+	var rated int
+	var ratingSum float64
+	for _, app := range apps {
+		stats.Categories[app.Category]++
+		if app.Type == "Paid" {
+			stats.PaidApps++
+		} else {
+			stats.FreeApps++
+		}
+		// NaN ratings are apps nobody rated yet
+		if !isNaN(app.Rating) {
+			rated++
+			ratingSum += app.Rating
+		}
+	}
+	if rated > 0 {
+		stats.AverageRating = ratingSum / float64(rated)
+	}
+	for _, review := range reviews {
+		stats.Sentiments[review.Sentiment]++
+	}
+
+	cacheStats(generation, stats)
+	return stats, nil
+}
+
+// cacheStats: Caches stats computed at generation, unless the data was
+// invalidated since
+func cacheStats(generation uint64, stats DatasetStats) {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	if generation == statsGeneration {
+		statsCache = &stats
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestStatsInvalidate(t *testing.T) {
+	stats := NewStatsModel(zap.NewNop(), config.AppConfig{})
+	statsCache = &DatasetStats{Apps: 1}
+	t.Cleanup(func() { statsCache = nil })
+
+	stats.Invalidate(context.Background(), Mutation{Action: ActionPurge, Entity: EntityTrash})
+	if statsCache == nil {
+		t.Fatal("a trash purge changes no apps or reviews and must keep the cached stats")
+	}
+
+	for _, entity := range []string{EntityApp, EntityReview, EntityDataset} {
+		statsCache = &DatasetStats{Apps: 1}
+		stats.Invalidate(context.Background(), Mutation{Action: ActionUpdate, Entity: entity})
+		if statsCache != nil {
+			t.Errorf("a %s mutation kept the cached stats", entity)
+		}
+	}
+}
+
+func TestStatsRefreshKeepsLaterInvalidation(t *testing.T) {
+	stats := NewStatsModel(zap.NewNop(), config.AppConfig{})
+	statsCache = nil
+	t.Cleanup(func() { statsCache = nil })
+
+	// A refresh that read the data before a mutation finished after it
+	generation := statsGeneration
+	stats.Invalidate(context.Background(), Mutation{Action: ActionCreate, Entity: EntityApp})
+	cacheStats(generation, DatasetStats{Apps: 1})
+	if statsCache != nil {
+		t.Fatal("a refresh started before an invalidation cached its stale stats")
+	}
+
+	cacheStats(statsGeneration, DatasetStats{Apps: 2})
+	if statsCache == nil || statsCache.Apps != 2 {
+		t.Errorf("cached stats = %+v; want the refresh after the invalidation", statsCache)
+	}
+}
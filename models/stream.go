@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// csvBatchSize is the number of records a worker decodes at a time
+const csvBatchSize = 512
+
+// csvItem is a record read from a CSV file and, once decoded, its row
+type csvItem[T any] struct {
+	line   int
+	record []string
+	row    T
+	err    error
+	skip   bool
+}
+
+// csvBatch is a run of consecutive records, seq orders the batches
+type csvBatch[T any] struct {
+	seq   int
+	items []csvItem[T]
+}
+
+// streamCSV: Reads the CSV file r in the dialect of opts and decodes its
+// rows as T on workers goroutines, one per CPU when workers is 0. prepare
+// runs on each decoded row on the same goroutines, to normalize it, and
+// returns false to drop it; it may be nil. Rows and bad records reach row
+// and bad in file order, as with eachCSVRow. At most a few batches per
+// worker are held at a time, memory does not grow with the file.
+func streamCSV[T any](r io.Reader, opts csvOptions, workers int, prepare func(*T) bool, row func(line int, v T), bad func(line int, record []string, err error)) error {
+	reader := opts.dialect.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	schema := newCSVSchema[T](header, opts.aliases)
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	decoders := make([]*rowDecoder[T], workers)
+	for i := range decoders {
+		if decoders[i], err = newRowDecoder[T](schema); err != nil {
+			return err
+		}
+	}
+
+	jobs := make(chan *csvBatch[T], workers)
+	results := make(chan *csvBatch[T], workers)
+	// A token is taken per batch read and given back once it is handed on
+	tokens := make(chan struct{}, 2*workers)
+
+	// Records are read on one goroutine, the CSV format cannot be split
+	var readErr error
+	go func() {
+		defer close(jobs)
+		for seq := 0; ; seq++ {
+			tokens <- struct{}{}
+			batch := &csvBatch[T]{seq: seq, items: make([]csvItem[T], 0, csvBatchSize)}
+			for len(batch.items) < csvBatchSize {
+				record, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					batch.items = append(batch.items, csvItem[T]{line: parseErr.StartLine, err: parseErr})
+					continue
+				}
+				if err != nil {
+					readErr = err
+					break
+				}
+				line, _ := reader.FieldPos(0)
+				batch.items = append(batch.items, csvItem[T]{line: line, record: record})
+			}
+			jobs <- batch
+			if len(batch.items) < csvBatchSize {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, decoder := range decoders {
+		wg.Add(1)
+		go func(decoder *rowDecoder[T]) {
+			defer wg.Done()
+			for batch := range jobs {
+				for i := range batch.items {
+					item := &batch.items[i]
+					if item.err != nil {
+						continue
+					}
+					item.row, item.err = decoder.decode(item.record)
+					if item.err == nil && prepare != nil {
+						item.skip = !prepare(&item.row)
+					}
+				}
+				results <- batch
+			}
+		}(decoder)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Batches finish out of order, they are handed on by seq
+	pending := map[int]*csvBatch[T]{}
+	next := 0
+	for batch := range results {
+		pending[batch.seq] = batch
+		for ready, ok := pending[next]; ok; ready, ok = pending[next] {
+			delete(pending, next)
+			next++
+			for _, item := range ready.items {
+				switch {
+				case item.err != nil:
+					bad(item.line, item.record, item.err)
+				case !item.skip:
+					row(item.line, item.row)
+				}
+			}
+			<-tokens
+		}
+	}
+	// The reader is done once results is closed
+	return readErr
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/csvdialect"
+)
+
+const appsHeader = "App,Category,Rating,Reviews,Size,Installs,Type,Price,Content Rating,Genres,Last Updated,Current Ver,Android Ver\n"
+
+// appsFile returns an apps CSV file of n rows. Every 97th row has a rating
+// that does not decode and every 13th row is a game.
+func appsFile(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(appsHeader)
+	for i := 0; i < n; i++ {
+		rating, category := "4.1", "TOOLS"
+		if i%97 == 0 {
+			rating = "bad"
+		}
+		if i%13 == 0 {
+			category = "GAME"
+		}
+		fmt.Fprintf(&buf, "App %d,%s,%s,%d,10M,\"1,000+\",Paid,$0.99,Everyone,Tools,\"May 2, 2018\",1.0,4.1 and up\n", i, category, rating, i)
+	}
+	return buf.Bytes()
+}
+
+func TestStreamCSVKeepsFileOrder(t *testing.T) {
+	// Several batches per worker, so batches finish out of order
+	const rows = 5*csvBatchSize + 100
+	opts := csvOptions{dialect: csvdialect.Default}
+	dropGames := func(app *App) bool { return app.Category != "GAME" }
+
+	for _, workers := range []int{1, 4, 16} {
+		var got []string
+		err := streamCSV(bytes.NewReader(appsFile(rows)), opts, workers, dropGames, func(line int, app App) {
+			got = append(got, fmt.Sprintf("%d:%s", line, app.Name))
+		}, func(line int, _ []string, _ error) {
+			got = append(got, fmt.Sprintf("%d:bad", line))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want []string
+		for i := 0; i < rows; i++ {
+			switch {
+			case i%97 == 0:
+				want = append(want, fmt.Sprintf("%d:bad", i+2))
+			case i%13 != 0:
+				want = append(want, fmt.Sprintf("%d:App %d", i+2, i))
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%d workers: rows are out of file order", workers)
+		}
+	}
+}
+
+func BenchmarkStreamCSV(b *testing.B) {
+	data := appsFile(20000)
+	opts := csvOptions{dialect: csvdialect.Default}
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err := streamCSV(bytes.NewReader(data), opts, workers, prepareApp, func(int, App) {}, func(int, []string, error) {})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteCSV(b *testing.B) {
+	var apps []App
+	err := streamCSV(bytes.NewReader(appsFile(20000)), csvOptions{dialect: csvdialect.Default}, 0, prepareApp, func(_ int, app App) {
+		apps = append(apps, app)
+	}, func(int, []string, error) {})
+	if err != nil {
+		b.Fatal(err)
+	}
+	schema := extendSchema(newCSVSchema[App](nil, nil), apps)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := writeCSV(io.Discard, csvdialect.Default, schema, apps, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
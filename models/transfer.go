@@ -0,0 +1,246 @@
+package models
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/jszwec/csvutil"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/csvdialect"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// File formats for import and export
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// RowError is a row of an imported file that was not imported
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// numbered is a decoded row with the line it was read from
+type numbered[T any] struct {
+	line int
+	row  T
+}
+
+// FormatFromPath: Returns the format matching the extension of path
+func FormatFromPath(path string) (string, error) {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case FormatCSV, FormatJSON, FormatNDJSON:
+		return ext, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w: cannot tell the format of %s", ErrUnsupportedFormat, path)
+	}
+}
+
+// decodeRows: Reads rows of T from r in format and validates each one.
+// CSV is read in the dialect of opts, its columns matched by name after
+// resolving aliases.
+// Rows that do not decode or validate are skipped and reported with
+// their line; an error is only returned when r cannot be read at all.
+func decodeRows[T any](r io.Reader, format string, opts csvOptions) ([]numbered[T], []RowError, error) {
+	var rows []numbered[T]
+	var rowErrors []RowError
+	keep := func(line int, row T) {
+		if err := utils.ValidateStruct(row); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			return
+		}
+		rows = append(rows, numbered[T]{line: line, row: row})
+	}
+
+	switch format {
+	case FormatCSV:
+		reader := opts.dialect.NewReader(r)
+		header, err := reader.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+		err = eachCSVRow(reader, newCSVSchema[T](header, opts.aliases), keep, func(line int, _ []string, err error) {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var row T
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			keep(line, row)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+
+	case FormatJSON:
+		// A JSON array has no meaningful lines, rows are numbered instead
+		var raw []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		for i, item := range raw {
+			var row T
+			if err := json.Unmarshal(item, &row); err != nil {
+				rowErrors = append(rowErrors, RowError{Line: i + 1, Message: err.Error()})
+				continue
+			}
+			keep(i+1, row)
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	return rows, rowErrors, nil
+}
+
+// errFieldCount is reported for records whose length differs from the header
+var errFieldCount = errors.New("wrong number of fields, columns may be shifted")
+
+// eachCSVRow: Reads every record after the header from reader, decoding
+// each one as T on its own through schema. The reader leaves records of
+// the wrong length to eachCSVRow instead of failing the file; the columns
+// without a field become the row metadata. Decoded rows go to row; records
+// that are not valid CSV, have the wrong number of fields or hold a value
+// of the wrong type go to bad with their line and a *csv.ParseError, an
+// error wrapping errFieldCount or a *csvutil.DecodeError. The error is only
+// set when reader fails.
+func eachCSVRow[T any](reader *csvdialect.Reader, schema csvSchema, row func(line int, v T), bad func(line int, record []string, err error)) error {
+	decoder, err := newRowDecoder[T](schema)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			bad(parseErr.StartLine, nil, parseErr)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		v, err := decoder.decode(record)
+		if err != nil {
+			bad(line, record, err)
+			continue
+		}
+		row(line, v)
+	}
+}
+
+// rowDecoder decodes records of a schema as T one at a time. It is not
+// safe for concurrent use, parallel decoding uses one per goroutine.
+type rowDecoder[T any] struct {
+	schema  csvSchema
+	source  *recordSource
+	decoder *csvutil.Decoder
+}
+
+// newRowDecoder: Returns a decoder of the records of schema. A schema
+// without a column filling a field is rejected, csvutil would read its
+// header from the records instead.
+func newRowDecoder[T any](schema csvSchema) (*rowDecoder[T], error) {
+	header := schema.decoderHeader()
+	if len(header) == 0 {
+		return nil, fmt.Errorf("%w: got %q", ErrNoKnownColumns, schema.header)
+	}
+	source := &recordSource{}
+	decoder, err := csvutil.NewDecoder(source, header...)
+	if err != nil {
+		return nil, err
+	}
+	return &rowDecoder[T]{schema: schema, source: source, decoder: decoder}, nil
+}
+
+// decode: Decodes record as T, its columns without a field become the
+// row metadata
+func (d *rowDecoder[T]) decode(record []string) (T, error) {
+	var v T
+	if len(record) != len(d.schema.header) {
+		return v, fmt.Errorf("%w: expected %d, got %d", errFieldCount, len(d.schema.header), len(record))
+	}
+
+	values, metadata := d.schema.split(record)
+	d.source.record = values
+	if err := d.decoder.Decode(&v); err != nil {
+		return v, err
+	}
+	if withMeta, ok := any(&v).(withMetadata); ok {
+		withMeta.setMetadata(metadata)
+	}
+	return v, nil
+}
+
+// recordSource feeds a csvutil.Decoder one record at a time, so each row is
+// decoded on its own after its length was checked
+type recordSource struct {
+	record []string
+}
+
+// Read: Returns the pending record once
+func (s *recordSource) Read() ([]string, error) {
+	if s.record == nil {
+		return nil, io.EOF
+	}
+	record := s.record
+	s.record = nil
+	return record, nil
+}
+
+// encodeRows: Writes rows to w in format, CSV in the dialect of opts
+func encodeRows[T any](w io.Writer, rows []T, format string, opts csvOptions) error {
+	switch format {
+	case FormatCSV:
+		// The header is written even without rows, metadata columns follow the fields
+		return writeCSV(w, opts.dialect, extendSchema(newCSVSchema[T](nil, nil), rows), rows, nil)
+
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatJSON:
+		if rows == nil {
+			rows = []T{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
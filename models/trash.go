@@ -0,0 +1,262 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Kinds of rows kept in the trash
+const (
+	TrashKindApp    = "app"
+	TrashKindReview = "review"
+)
+
+// TrashEntry holds the rows removed by one delete
+type TrashEntry struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	Apps      []App     `json:"apps,omitempty"`
+	Reviews   []Review  `json:"reviews,omitempty"`
+}
+
+type TrashModel struct {
+	logger *zap.Logger
+	config config.AppConfig
+}
+
+// NewTrashModel initializes a new TrashModel instance
+func NewTrashModel(logger *zap.Logger, config config.AppConfig) *TrashModel {
+	return &TrashModel{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Global Trash Variables. Deletes take appMutex or reviewMutex before
+// trashMutex, never the other way around. trashFile is the trash file as
+// last read or written, another process such as the purge command may
+// replace it.
+var (
+	trashEntries []TrashEntry
+	trashLoaded  bool
+	trashFile    os.FileInfo
+	trashMutex   sync.Mutex
+)
+
+// log: Returns the request-scoped logger carried by ctx, or the model logger
+func (tm *TrashModel) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, tm.logger)
+}
+
+// ListTrash: Returns the trash entries of the given kind, newest first.
+// An empty kind lists every entry.
+func (tm *TrashModel) ListTrash(ctx context.Context, kind string) ([]TrashEntry, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(tm.config.TrashFilePath); err != nil {
+		tm.log(ctx).Error("Error loading trash", zap.Error(err))
+		return nil, err
+	}
+
+	entries := []TrashEntry{}
+	for i := len(trashEntries) - 1; i >= 0; i-- {
+		if kind == "" || trashEntries[i].Kind == kind {
+			entries = append(entries, trashEntries[i])
+		}
+	}
+	return entries, nil
+}
+
+// Purge: Permanently removes entries deleted more than olderThan ago.
+// Returns the number of entries removed.
+func (tm *TrashModel) Purge(ctx context.Context, olderThan time.Duration) (purged int, err error) {
+	_, span := tracing.Start(ctx, "TrashModel.Purge",
+		trace.WithAttributes(attribute.String("trash.older_than", olderThan.String())))
+	defer func() {
+		span.SetAttributes(attribute.Int("trash.purged", purged))
+		tracing.End(span, err)
+	}()
+
+	done, err := beginWrite()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(tm.config.TrashFilePath); err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := make([]TrashEntry, 0, len(trashEntries))
+	var purgedEntries []TrashEntry
+	for _, entry := range trashEntries {
+		if entry.DeletedAt.After(cutoff) {
+			kept = append(kept, entry)
+		} else {
+			purgedEntries = append(purgedEntries, entry)
+		}
+	}
+
+	purged = len(trashEntries) - len(kept)
+	if purged == 0 {
+		return 0, nil
+	}
+	if err := saveTrash(tm.config.TrashFilePath, kept); err != nil {
+		tm.log(ctx).Error("Error writing trash", zap.Error(err))
+		return 0, err
+	}
+	notifyMutation(ctx, Mutation{
+		Action: ActionPurge,
+		Entity: EntityTrash,
+		Before: purgedEntries,
+	})
+	return purged, nil
+}
+
+// moveToTrash: Stores deleted rows as a new trash entry stamped with the
+// actor carried by ctx. It returns the entry ID so a failed delete can
+// take it back out with dropFromTrash.
+func moveToTrash(ctx context.Context, path string, entry TrashEntry) (string, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(path); err != nil {
+		return "", err
+	}
+
+	entry.ID = uuid.NewString()
+	entry.DeletedAt = time.Now().UTC()
+	entry.DeletedBy = utils.ActorFromContext(ctx)
+
+	entries := append(append([]TrashEntry{}, trashEntries...), entry)
+	if err := saveTrash(path, entries); err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// takeFromTrash: Removes and returns the most recent entry of kind for name
+func takeFromTrash(path, kind, name string) (TrashEntry, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(path); err != nil {
+		return TrashEntry{}, err
+	}
+
+	for i := len(trashEntries) - 1; i >= 0; i-- {
+		entry := trashEntries[i]
+		if entry.Kind != kind || entry.Name != name {
+			continue
+		}
+		entries := append(append([]TrashEntry{}, trashEntries[:i]...), trashEntries[i+1:]...)
+		if err := saveTrash(path, entries); err != nil {
+			return TrashEntry{}, err
+		}
+		return entry, nil
+	}
+	return TrashEntry{}, fmt.Errorf("%w: no deleted %s named %s", ErrTrashEntryNotFound, kind, name)
+}
+
+// returnToTrash: Puts back an entry taken with takeFromTrash when the
+// restore could not be written
+func returnToTrash(path string, entry TrashEntry) error {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(path); err != nil {
+		return err
+	}
+	return saveTrash(path, append(append([]TrashEntry{}, trashEntries...), entry))
+}
+
+// dropFromTrash: Removes the entry with the given ID
+func dropFromTrash(path, id string) error {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	if err := loadTrash(path); err != nil {
+		return err
+	}
+
+	entries := make([]TrashEntry, 0, len(trashEntries))
+	for _, entry := range trashEntries {
+		if entry.ID != id {
+			entries = append(entries, entry)
+		}
+	}
+	return saveTrash(path, entries)
+}
+
+// loadTrash: Reads the trash file unless it is unchanged since it was last
+// read or written. The caller must hold trashMutex.
+func loadTrash(path string) error {
+	if path == "" {
+		return fmt.Errorf("trash %w", ErrFilePathNotConfigured)
+	}
+	info, changed, err := utils.FileChanged(path, trashFile)
+	if err != nil {
+		return err
+	}
+	if trashLoaded && !changed {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var entries []TrashEntry
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+	}
+	trashEntries = entries
+	trashLoaded = true
+	trashFile = info
+	return nil
+}
+
+// saveTrash: Atomically rewrites the trash file and replaces the loaded
+// entries. The caller must hold trashMutex.
+func saveTrash(path string, entries []TrashEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+
+	trashEntries = entries
+	// Unless it can be stat, the file is read again next time
+	trashFile, err = os.Stat(path)
+	trashLoaded = err == nil
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+func TestTrashSeesChangesFromOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trash.json")
+	cfg := config.AppConfig{TrashFilePath: path}
+	server := NewTrashModel(zap.NewNop(), cfg)
+	ctx := context.Background()
+
+	if _, err := moveToTrash(ctx, path, TrashEntry{Kind: TrashKindApp, Name: "Old"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := server.ListTrash(ctx, ""); err != nil || len(entries) != 1 {
+		t.Fatalf("ListTrash = %d entries, %v; want 1", len(entries), err)
+	}
+
+	// The purge command runs in its own process and rewrites the file
+	if err := os.WriteFile(path, []byte("[]"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, err := server.ListTrash(ctx, ""); err != nil || len(entries) != 0 {
+		t.Fatalf("ListTrash after purge = %d entries, %v; want the purged file", len(entries), err)
+	}
+	if _, err := moveToTrash(ctx, path, TrashEntry{Kind: TrashKindApp, Name: "New"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := server.ListTrash(ctx, "")
+	if err != nil || len(entries) != 1 || entries[0].Name != "New" {
+		t.Fatalf("ListTrash = %+v, %v; the purged entry must not come back", entries, err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package csvdialect reads and writes CSV files that are not plain UTF-8
+// comma separated values: other delimiters, comment lines, loose quoting,
+// legacy encodings and byte order marks.
+package csvdialect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the UTF-8 encoding of the byte order mark U+FEFF
+var utf8BOM = []byte("\uFEFF")
+
+// Dialect describes the format of a CSV file. The zero value is not
+// usable, start from Default or Parse.
+type Dialect struct {
+	// Comma separates the fields of a record
+	Comma rune
+	// Comment starts lines that are skipped when reading, 0 for none
+	Comment rune
+	// LazyQuotes accepts quotes inside unquoted fields and unescaped
+	// quotes inside quoted fields
+	LazyQuotes bool
+	// TrimSpace removes leading and trailing white space from fields
+	TrimSpace bool
+	// Encoding is the encoding of the file, it is transcoded from and to UTF-8
+	Encoding encoding.Encoding
+	// BOM writes a byte order mark at the start of the file. A leading
+	// UTF-8 byte order mark is stripped when reading either way.
+	BOM bool
+}
+
+// Default is comma separated UTF-8 without a byte order mark
+var Default = Dialect{Comma: ',', Encoding: unicode.UTF8}
+
+// Parse builds a dialect from its settings. delimiter and comment are a
+// single character, or "\t" or "tab" for a tab; encodingName is a WHATWG
+// encoding label such as utf-8, latin1, windows-1252 or utf-16le. On error
+// Default is returned.
+func Parse(delimiter, comment, encodingName string, lazyQuotes, trimSpace, bom bool) (Dialect, error) {
+	comma, err := parseRune(delimiter)
+	if err != nil {
+		return Default, fmt.Errorf("delimiter: %w", err)
+	}
+	if comma == 0 || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		return Default, fmt.Errorf("delimiter: %q cannot separate fields", delimiter)
+	}
+
+	commentRune, err := parseRune(comment)
+	if err != nil {
+		return Default, fmt.Errorf("comment: %w", err)
+	}
+	if commentRune == comma || commentRune == '"' || commentRune == '\r' || commentRune == '\n' {
+		return Default, fmt.Errorf("comment: %q cannot start comments with delimiter %q", comment, delimiter)
+	}
+
+	enc := Default.Encoding
+	if encodingName != "" {
+		if enc, err = htmlindex.Get(encodingName); err != nil {
+			return Default, fmt.Errorf("encoding: unknown encoding %q", encodingName)
+		}
+	}
+
+	return Dialect{
+		Comma:      comma,
+		Comment:    commentRune,
+		LazyQuotes: lazyQuotes,
+		TrimSpace:  trimSpace,
+		Encoding:   enc,
+		BOM:        bom,
+	}, nil
+}
+
+// parseRune returns the single character of s, 0 when s is empty
+func parseRune(s string) (rune, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) {
+		return 0, fmt.Errorf("expected a single character, got %q", s)
+	}
+	return r, nil
+}
+
+// Reader reads records in a dialect. Lines of its errors and FieldPos are
+// counted in the transcoded file.
+type Reader struct {
+	*csv.Reader
+	trimSpace bool
+}
+
+// Decode returns r transcoded to UTF-8 without its byte order mark, the
+// text NewReader parses and counts lines in
+func (d Dialect) Decode(r io.Reader) io.Reader {
+	src := stripBOM(r)
+	if d.Encoding != nil && d.Encoding != unicode.UTF8 {
+		// A byte order mark of the file encoding is stripped once decoded
+		src = stripBOM(transform.NewReader(src, d.Encoding.NewDecoder()))
+	}
+	return src
+}
+
+// NewReader returns a reader transcoding r to UTF-8 and stripping its byte
+// order mark. Records of any length are returned, callers compare them
+// with the header.
+func (d Dialect) NewReader(r io.Reader) *Reader {
+	reader := csv.NewReader(d.Decode(r))
+	reader.Comma = d.Comma
+	reader.Comment = d.Comment
+	reader.LazyQuotes = d.LazyQuotes
+	reader.TrimLeadingSpace = d.TrimSpace
+	reader.FieldsPerRecord = -1
+	return &Reader{Reader: reader, trimSpace: d.TrimSpace}
+}
+
+// Read returns the next record, trimmed when the dialect trims fields
+func (r *Reader) Read() ([]string, error) {
+	record, err := r.Reader.Read()
+	if r.trimSpace {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+	}
+	return record, err
+}
+
+// Writer writes records in a dialect
+type Writer struct {
+	*csv.Writer
+	out     io.Writer
+	dst     io.Writer
+	encoder *transform.Writer
+	bom     []byte
+}
+
+// NewWriter returns a writer transcoding records from UTF-8 to the dialect
+// encoding. Close must be called to flush them.
+func (d Dialect) NewWriter(w io.Writer) *Writer {
+	writer := &Writer{out: w}
+	if d.BOM {
+		writer.bom = d.bom()
+	}
+	dst := w
+	if d.Encoding != nil && d.Encoding != unicode.UTF8 {
+		writer.encoder = transform.NewWriter(w, d.Encoding.NewEncoder())
+		dst = writer.encoder
+	}
+	writer.dst = dst
+	writer.Writer = csv.NewWriter(dst)
+	writer.Writer.Comma = d.Comma
+	return writer
+}
+
+// WriteRaw writes text, lines in UTF-8, as it is followed by a line break.
+// It is for lines that are not valid CSV and cannot go through Write.
+func (w *Writer) WriteRaw(text string) error {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.dst, text+"\n")
+	return err
+}
+
+// WriteHeader writes the byte order mark of the dialect followed by header.
+// Records appended to an existing file are written without a header, and
+// so without a byte order mark.
+func (w *Writer) WriteHeader(header []string) error {
+	if len(w.bom) > 0 {
+		if _, err := w.out.Write(w.bom); err != nil {
+			return err
+		}
+	}
+	return w.Write(header)
+}
+
+// Close flushes the records written so far. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if w.encoder != nil {
+		if err := w.encoder.Close(); err != nil {
+			return fmt.Errorf("encoding CSV: %w", err)
+		}
+	}
+	return nil
+}
+
+// bom returns the byte order mark in the dialect encoding, or the UTF-8
+// one for encodings that cannot represent it
+func (d Dialect) bom() []byte {
+	if d.Encoding == nil || d.Encoding == unicode.UTF8 {
+		return utf8BOM
+	}
+	bom, err := d.Encoding.NewEncoder().Bytes(utf8BOM)
+	if err != nil {
+		return utf8BOM
+	}
+	return bom
+}
+
+// stripBOM returns r without a leading UTF-8 byte order mark
+func stripBOM(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+	return buffered
+}
@@ -0,0 +1,114 @@
+package csvdialect
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"App", "Category", "Price"},
+		{"Café Façade", "FOOD_AND_DRINK", "$1.99"},
+		{"Semi;colon, comma", "TOOLS", "0"},
+		{"Line\nbreak \"quoted\"", "TOOLS", "0"},
+	}
+
+	tests := []struct {
+		name      string
+		delimiter string
+		encoding  string
+		bom       bool
+		bomBytes  []byte
+	}{
+		{"default", ",", "", false, nil},
+		{"semicolon with BOM", ";", "utf-8", true, []byte("\xEF\xBB\xBF")},
+		{"tab latin1", "tab", "latin1", false, nil},
+		{"utf-16le with BOM", "|", "utf-16le", true, []byte{0xFF, 0xFE}},
+	}
+	for _, tt := range tests {
+		dialect, err := Parse(tt.delimiter, "", tt.encoding, false, false, tt.bom)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var buf bytes.Buffer
+		writer := dialect.NewWriter(&buf)
+		if err := writer.WriteHeader(records[0]); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, record := range records[1:] {
+			if err := writer.Write(record); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.bomBytes != nil && !bytes.HasPrefix(buf.Bytes(), tt.bomBytes) {
+			t.Errorf("%s: file starts with % x; want the byte order mark % x", tt.name, buf.Bytes()[:4], tt.bomBytes)
+		}
+		if tt.encoding == "latin1" && bytes.Contains(buf.Bytes(), []byte("é")) {
+			t.Errorf("%s: file holds UTF-8, want latin1", tt.name)
+		}
+
+		got, err := readAll(dialect.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Errorf("%s: read back %q; want %q", tt.name, got, records)
+		}
+	}
+}
+
+func TestReaderTrimsAndSkipsComments(t *testing.T) {
+	dialect, err := Parse(";", "#", "", false, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := "\xEF\xBB\xBFApp ; Rating\n# exported by hand\n  Maps ;4.3  \n"
+
+	got, err := readAll(dialect.NewReader(bytes.NewBufferString(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"App", "Rating"}, {"Maps", "4.3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("read %q; want %q", got, want)
+	}
+}
+
+func TestParseRejectsBadSettings(t *testing.T) {
+	tests := []struct {
+		name                         string
+		delimiter, comment, encoding string
+	}{
+		{"empty delimiter", "", "", ""},
+		{"quote delimiter", `"`, "", ""},
+		{"two characters", ";;", "", ""},
+		{"comment is the delimiter", ";", ";", ""},
+		{"unknown encoding", ",", "", "klingon"},
+	}
+	for _, tt := range tests {
+		if dialect, err := Parse(tt.delimiter, tt.comment, tt.encoding, false, false, false); err == nil {
+			t.Errorf("%s: Parse = %+v; want an error", tt.name, dialect)
+		}
+	}
+}
+
+// readAll reads every record of reader
+func readAll(reader *Reader) ([][]string, error) {
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
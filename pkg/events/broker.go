@@ -0,0 +1,127 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it is dropped. Dropped clients reconnect with Last-Event-ID.
+const subscriberBuffer = 64
+
+// Event is one change published to the feed
+type Event struct {
+	ID       uint64      `json:"id"`
+	Type     string      `json:"type"`
+	Resource string      `json:"resource"`
+	App      string      `json:"app,omitempty"`
+	Time     time.Time   `json:"time"`
+	Data     interface{} `json:"data,omitempty"`
+}
+
+// Broker fans published events out to subscribers and keeps the latest
+// events in a ring buffer so clients can resume after a disconnect
+type Broker struct {
+	mu          sync.Mutex
+	buffer      []Event
+	start       int
+	nextID      uint64
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker returns a broker remembering the last size events
+func NewBroker(size int) *Broker {
+	if size < 1 {
+		size = 1
+	}
+	return &Broker{
+		buffer: make([]Event, 0, size),
+		// IDs continue from the start time so an ID handed out before a
+		// restart is never mistaken for one of this process. Milliseconds
+		// keep them below 2^53 for JavaScript clients.
+		nextID:      uint64(time.Now().UnixMilli()) * 1000,
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Publish assigns the next ID to event, buffers it and delivers it to
+// every subscriber
+func (b *Broker) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	if len(b.buffer) < cap(b.buffer) {
+		b.buffer = append(b.buffer, event)
+	} else {
+		b.buffer[b.start] = event
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Too slow, let the client catch up through Last-Event-ID
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a new subscriber. Events after lastID that are
+// still buffered are returned as backlog; complete is false when some
+// of them were already evicted. A zero lastID starts from new events.
+// The channel is closed when the subscriber is dropped or cancelled.
+func (b *Broker) Subscribe(lastID uint64) (backlog []Event, complete bool, events <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastID != 0 {
+		buffered := b.buffered()
+		oldest := b.nextID + 1
+		if len(buffered) > 0 {
+			oldest = buffered[0].ID
+		}
+		complete = lastID+1 >= oldest && lastID <= b.nextID
+		for _, event := range buffered {
+			if event.ID > lastID {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return backlog, complete, ch, cancel
+}
+
+// buffered returns the buffered events oldest first, the caller must hold b.mu
+func (b *Broker) buffered() []Event {
+	return append(append([]Event{}, b.buffer[b.start:]...), b.buffer[:b.start]...)
+}
+
+// TypeReset tells a resuming client that events were missed and it
+// should reload the resources it follows
+const TypeReset = "stream.reset"
+
+// Matches reports whether event passes the resource and app filters.
+// Empty filters match every event.
+func (e Event) Matches(resource, app string) bool {
+	return (resource == "" || e.Resource == resource) && (app == "" || e.App == app)
+}
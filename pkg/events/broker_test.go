@@ -0,0 +1,141 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+// publish publishes n events and returns their IDs
+func publish(b *Broker, n int) []uint64 {
+	ids := make([]uint64, n)
+	for i := range ids {
+		ids[i] = b.Publish(Event{Type: "app.created", Resource: "app"}).ID
+	}
+	return ids
+}
+
+// eventIDs returns the IDs of events
+func eventIDs(events []Event) []uint64 {
+	ids := []uint64{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestBufferWrapsAround(t *testing.T) {
+	b := NewBroker(3)
+	ids := publish(b, 7)
+
+	b.mu.Lock()
+	got := eventIDs(b.buffered())
+	b.mu.Unlock()
+	if want := ids[4:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("buffered = %v; want the last three events oldest first %v", got, want)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] != ids[i-1]+1 {
+			t.Fatalf("IDs %v are not consecutive", ids)
+		}
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	b := NewBroker(4)
+	ids := publish(b, 10) // ids[6:] are buffered
+
+	tests := []struct {
+		name         string
+		lastID       uint64
+		wantBacklog  []uint64
+		wantComplete bool
+	}{
+		{"new subscriber", 0, []uint64{}, true},
+		{"up to date", ids[9], []uint64{}, true},
+		{"within the buffer", ids[7], ids[8:], true},
+		{"just before the oldest buffered event", ids[5], ids[6:], true},
+		{"after eviction", ids[4], ids[6:], false},
+		{"long gone", ids[0], ids[6:], false},
+		{"ID from another process", ids[9] + 100, []uint64{}, false},
+	}
+	for _, tt := range tests {
+		backlog, complete, _, cancel := b.Subscribe(tt.lastID)
+		cancel()
+		if got := eventIDs(backlog); !reflect.DeepEqual(got, tt.wantBacklog) {
+			t.Errorf("%s: backlog = %v; want %v", tt.name, got, tt.wantBacklog)
+		}
+		// An incomplete backlog is what makes the stream send a reset
+		if complete != tt.wantComplete {
+			t.Errorf("%s: complete = %v; want %v", tt.name, complete, tt.wantComplete)
+		}
+	}
+}
+
+func TestSubscribeReceivesNewEvents(t *testing.T) {
+	b := NewBroker(4)
+	_, _, events, cancel := b.Subscribe(0)
+	ids := publish(b, 3)
+
+	for _, id := range ids {
+		if event := <-events; event.ID != id {
+			t.Errorf("received event %d; want %d", event.ID, id)
+		}
+	}
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("channel still open after cancel")
+	}
+	// Cancelling twice must not close the channel again
+	cancel()
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	b := NewBroker(4)
+	_, _, slow, cancelSlow := b.Subscribe(0)
+	defer cancelSlow()
+	_, _, fast, cancelFast := b.Subscribe(0)
+	defer cancelFast()
+
+	received := 0
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(Event{Type: "app.updated"})
+		<-fast
+		received++
+	}
+
+	// The slow subscriber gets the buffered events and then a closed channel
+	for i := 0; i < subscriberBuffer; i++ {
+		if _, ok := <-slow; !ok {
+			t.Fatalf("channel closed after %d events; want %d", i, subscriberBuffer)
+		}
+	}
+	if _, ok := <-slow; ok {
+		t.Error("slow subscriber was not dropped")
+	}
+
+	b.mu.Lock()
+	subscribers := len(b.subscribers)
+	b.mu.Unlock()
+	if subscribers != 1 || received != subscriberBuffer+1 {
+		t.Errorf("%d subscribers left, fast one received %d events; want 1 and %d", subscribers, received, subscriberBuffer+1)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	event := Event{Resource: "review", App: "Maps"}
+	tests := []struct {
+		resource, app string
+		want          bool
+	}{
+		{"", "", true},
+		{"review", "", true},
+		{"review", "Maps", true},
+		{"app", "", false},
+		{"", "Mail", false},
+	}
+	for _, tt := range tests {
+		if got := event.Matches(tt.resource, tt.app); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v; want %v", tt.resource, tt.app, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package health
+
+import "sync/atomic"
+
+// draining is set once shutdown starts so readiness fails while
+// in-flight requests finish
+var draining atomic.Bool
+
+// SetDraining marks the server as shutting down
+func SetDraining() {
+	draining.Store(true)
+}
+
+// Draining reports whether shutdown has started
+func Draining() bool {
+	return draining.Load()
+}
@@ -0,0 +1,152 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// Errors returned by Begin
+var (
+	ErrKeyInFlight = errors.New("a request with this idempotency key is still being processed")
+	ErrKeyReused   = errors.New("idempotency key was already used with a different request")
+	ErrKeyTooLong  = errors.New("idempotency key must not be longer than 255 characters")
+)
+
+// MaxKeyLength is the longest accepted Idempotency-Key header
+const MaxKeyLength = 255
+
+// Record is the stored outcome of the first request made with a key
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Store keeps responses per idempotency key for a TTL. Completed records
+// are persisted to a JSON file so replays survive restarts, keys being
+// processed are only tracked in memory.
+type Store struct {
+	path     string
+	ttl      time.Duration
+	mu       sync.Mutex
+	records  map[string]Record
+	inFlight map[string]string
+}
+
+// NewStore loads the store persisted at path. An empty path keeps
+// records in memory only.
+func NewStore(path string, ttl time.Duration) (*Store, error) {
+	store := &Store{
+		path:     path,
+		ttl:      ttl,
+		records:  map[string]Record{},
+		inFlight: map[string]string{},
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.records); err != nil {
+			return nil, err
+		}
+	}
+	store.purgeExpired(time.Now())
+	return store, nil
+}
+
+// Begin reserves key for a request with the given fingerprint.
+// It returns the stored record if the key was already completed with the
+// same fingerprint, or nil if the caller should process the request and
+// then call Complete or Release.
+func (s *Store) Begin(key, fingerprint string) (*Record, error) {
+	if len(key) > MaxKeyLength {
+		return nil, ErrKeyTooLong
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		if time.Now().Before(record.ExpiresAt) {
+			if record.Fingerprint != fingerprint {
+				return nil, ErrKeyReused
+			}
+			return &record, nil
+		}
+		delete(s.records, key)
+	}
+
+	if inFlight, ok := s.inFlight[key]; ok {
+		if inFlight != fingerprint {
+			return nil, ErrKeyReused
+		}
+		return nil, ErrKeyInFlight
+	}
+
+	s.inFlight[key] = fingerprint
+	return nil, nil
+}
+
+// Complete stores the response for a key reserved with Begin
+func (s *Store) Complete(key string, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	record.Fingerprint = s.inFlight[key]
+	record.CreatedAt = now
+	record.ExpiresAt = now.Add(s.ttl)
+
+	delete(s.inFlight, key)
+	s.records[key] = record
+	s.purgeExpired(now)
+
+	return s.persist()
+}
+
+// Release drops the reservation of a key without storing a response,
+// so the request can be retried with the same key
+func (s *Store) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, key)
+}
+
+// purgeExpired removes expired records, the caller must hold s.mu
+func (s *Store) purgeExpired(now time.Time) {
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// persist atomically rewrites the store file, the caller must hold s.mu
+func (s *Store) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s.records)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(s.path, data, 0o600)
+}
@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBeginCompleteReplay(t *testing.T) {
+	store, err := NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := store.Begin("key", "a")
+	if err != nil || record != nil {
+		t.Fatalf("first Begin = %v, %v; want nil, nil", record, err)
+	}
+	if _, err := store.Begin("key", "a"); !errors.Is(err, ErrKeyInFlight) {
+		t.Fatalf("Begin while in flight = %v; want ErrKeyInFlight", err)
+	}
+	if _, err := store.Begin("key", "b"); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("Begin with another request while in flight = %v; want ErrKeyReused", err)
+	}
+
+	if err := store.Complete("key", Record{Status: 201, Body: []byte("created")}); err != nil {
+		t.Fatal(err)
+	}
+	record, err = store.Begin("key", "a")
+	if err != nil || record == nil || record.Status != 201 || string(record.Body) != "created" {
+		t.Fatalf("replay = %+v, %v; want the stored 201 response", record, err)
+	}
+	if _, err := store.Begin("key", "b"); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("Begin with another request = %v; want ErrKeyReused", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	store, _ := NewStore("", time.Hour)
+	if _, err := store.Begin("key", "a"); err != nil {
+		t.Fatal(err)
+	}
+	store.Release("key")
+	if record, err := store.Begin("key", "a"); err != nil || record != nil {
+		t.Fatalf("Begin after Release = %v, %v; want a new reservation", record, err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	store, _ := NewStore("", time.Nanosecond)
+	_, _ = store.Begin("key", "a")
+	if err := store.Complete("key", Record{Status: 200}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if record, err := store.Begin("key", "b"); err != nil || record != nil {
+		t.Fatalf("Begin after expiry = %v, %v; want a new reservation", record, err)
+	}
+}
+
+func TestKeyTooLong(t *testing.T) {
+	store, _ := NewStore("", time.Hour)
+	if _, err := store.Begin(strings.Repeat("k", MaxKeyLength+1), "a"); !errors.Is(err, ErrKeyTooLong) {
+		t.Fatalf("Begin = %v; want ErrKeyTooLong", err)
+	}
+}
+
+func TestPersistedRecordsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = store.Begin("done", "a")
+	if err := store.Complete("done", Record{Status: 201}); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = store.Begin("pending", "a")
+
+	reopened, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record, err := reopened.Begin("done", "a"); err != nil || record == nil || record.Status != 201 {
+		t.Fatalf("replay after restart = %+v, %v; want the stored response", record, err)
+	}
+	// Keys in flight are only tracked in memory
+	if record, err := reopened.Begin("pending", "a"); err != nil || record != nil {
+		t.Fatalf("Begin of a key in flight before restart = %v, %v; want a new reservation", record, err)
+	}
+}
@@ -0,0 +1,131 @@
+package listener
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
+)
+
+// Client certificate policies when a client CA bundle is configured
+const (
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+// PoolName is the job pool the certificate watcher runs on
+const PoolName = "tls-reload"
+
+// tlsVersions maps the accepted TLSMinVersion values
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// New opens the listener the API serves on: a Unix domain socket when
+// UnixSocketPath is set, the TCP host and port otherwise. With a TLS
+// certificate configured the listener terminates TLS, verifying client
+// certificates against TLSClientCAFile when it is set.
+func New(cfg config.AppConfig, logger *zap.Logger) (net.Listener, error) {
+	tlsConfig, err := newTLSConfig(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var ln net.Listener
+	if cfg.UnixSocketPath != "" {
+		ln, err = listenUnix(cfg.UnixSocketPath, os.FileMode(cfg.UnixSocketMode))
+	} else {
+		ln, err = net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		return tls.NewListener(ln, tlsConfig), nil
+	}
+	return ln, nil
+}
+
+// listenUnix listens on a Unix domain socket, replacing a socket left over
+// by a previous run. The socket file is removed when the listener closes.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// newTLSConfig returns nil when TLS is not configured
+func newTLSConfig(cfg config.AppConfig, logger *zap.Logger) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		if cfg.TLSClientCAFile != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return nil, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	minVersion, ok := tlsVersions[cfg.TLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS_MIN_VERSION %q, expected 1.2 or 1.3", cfg.TLSMinVersion)
+	}
+
+	reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.getCertificate,
+	}
+	if cfg.TLSClientCAFile != "" {
+		switch cfg.TLSClientAuth {
+		case ClientAuthRequire:
+			base.ClientAuth = tls.RequireAndVerifyClientCert
+		case ClientAuthOptional:
+			base.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unsupported TLS_CLIENT_AUTH %q, expected %s or %s", cfg.TLSClientAuth, ClientAuthRequire, ClientAuthOptional)
+		}
+	}
+
+	tlsConfig := base.Clone()
+	tlsConfig.GetConfigForClient = reloader.configForClient(base)
+
+	if cfg.TLSReloadInterval > 0 {
+		pool := routinewrapper.NewPool(PoolName, 1, 1)
+		_, err := pool.Submit("watch certificates", func(ctx context.Context) error {
+			return reloader.watch(ctx, cfg.TLSReloadInterval)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,147 @@
+package listener
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// certReloader serves the certificate and client CA bundle from disk,
+// loading them again whenever one of the files changes
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// newCertReloader loads the files once, failing when they are unusable
+func newCertReloader(certFile, keyFile, caFile string, logger *zap.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile, logger: logger}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load reads the certificate, key and CA bundle and swaps them in together
+func (r *certReloader) load() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client CA bundle has no certificates")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	return nil
+}
+
+// stat returns the modification time of every watched file
+func (r *certReloader) stat() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// changed reports whether a watched file was modified since the last load
+func (r *certReloader) changed() bool {
+	modTimes, err := r.stat()
+	if err != nil {
+		// Mid-rotation a file may briefly be missing, try again next tick
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch polls the files every interval until ctx is cancelled. A reload
+// that fails keeps serving the previous certificate.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if !r.changed() {
+			continue
+		}
+		if err := r.load(); err != nil {
+			r.logger.Error("error reloading TLS certificate, keeping the previous one", zap.Error(err))
+			continue
+		}
+		r.logger.Info("reloaded TLS certificate", zap.String("cert_file", r.certFile))
+	}
+}
+
+// getCertificate is the tls.Config hook serving the current certificate
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// configForClient returns base with the current client CA bundle, so a
+// rotated bundle applies to new handshakes
+func (r *certReloader) configForClient(base *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientCAs = r.clientCA
+		return cfg, nil
+	}
+}
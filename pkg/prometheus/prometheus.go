@@ -9,6 +9,12 @@ const Namespace = "golang_api"
 
 type PrometheusMetrics struct {
 	RequestsMetrics *prometheus.CounterVec
+
+	// Background jobs per pool
+	JobsQueued    *prometheus.GaugeVec
+	JobsRunning   *prometheus.GaugeVec
+	JobsCompleted *prometheus.CounterVec
+	JobsFailed    *prometheus.CounterVec
 }
 
 var metrics *PrometheusMetrics = nil
@@ -21,6 +27,26 @@ func InitPrometheusMetrics() *PrometheusMetrics {
 				Name:      "requests_total",
 				Help:      "Total API requests",
 			}, []string{"code"}),
+			JobsQueued: promauto.NewGaugeVec(prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Name:      "jobs_queued",
+				Help:      "Background jobs waiting for a worker",
+			}, []string{"pool"}),
+			JobsRunning: promauto.NewGaugeVec(prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Name:      "jobs_running",
+				Help:      "Background jobs being run",
+			}, []string{"pool"}),
+			JobsCompleted: promauto.NewCounterVec(prometheus.CounterOpts{
+				Namespace: Namespace,
+				Name:      "jobs_completed_total",
+				Help:      "Background jobs that finished without error",
+			}, []string{"pool"}),
+			JobsFailed: promauto.NewCounterVec(prometheus.CounterOpts{
+				Namespace: Namespace,
+				Name:      "jobs_failed_total",
+				Help:      "Background jobs that returned an error, panicked or were cancelled",
+			}, []string{"pool", "reason"}),
 		}
 	}
 
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Monday
+	monday := time.Date(2024, time.January, 15, 10, 17, 30, 0, time.UTC)
+	newYearsEve := time.Date(2024, time.December, 31, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", monday, time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", monday, time.Date(2024, time.January, 15, 10, 25, 0, 0, time.UTC)},
+		{"17 10 * * *", monday, time.Date(2024, time.January, 16, 10, 17, 0, 0, time.UTC)},
+		{"0 3 * * *", monday, time.Date(2024, time.January, 16, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", monday, time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", monday, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", monday, time.Date(2024, time.January, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", monday, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 12 13 * fri", monday, time.Date(2024, time.January, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", monday, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", newYearsEve, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", monday, time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s; want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded; want an error", spec)
+		}
+	}
+}
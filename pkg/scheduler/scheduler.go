@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
+)
+
+// PoolName is the job pool scheduled tasks run on
+const PoolName = "scheduler"
+
+// Task is a unit of scheduled work. The context is cancelled on shutdown.
+type Task func(ctx context.Context) error
+
+// TaskStatus reports the schedule and latest run of a task
+type TaskStatus struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Running      bool          `json:"running"`
+	NextRun      time.Time     `json:"next_run"`
+	LastStarted  *time.Time    `json:"last_started,omitempty"`
+	LastFinished *time.Time    `json:"last_finished,omitempty"`
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	// Runs skipped because the previous one was still going
+	Skipped int `json:"skipped"`
+}
+
+// entry is a registered task and its state
+type entry struct {
+	schedule *Schedule
+	task     Task
+	next     time.Time // time the schedule is due
+	fireAt   time.Time // next plus jitter
+	status   TaskStatus
+}
+
+// Scheduler runs registered tasks on their cron schedules. A task never
+// overlaps with itself: a run that comes due while the previous one is
+// still going is skipped.
+type Scheduler struct {
+	logger *zap.Logger
+	jitter time.Duration
+	pool   *routinewrapper.Pool
+
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// New returns a scheduler that delays every run by a random duration of
+// up to jitter, so instances sharing a schedule do not run in lockstep
+func New(logger *zap.Logger, jitter time.Duration) *Scheduler {
+	return &Scheduler{logger: logger, jitter: jitter}
+}
+
+// Register adds a task under name with a cron expression, see Parse
+func (s *Scheduler) Register(name, spec string, task Task) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", name, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("task %s: cron %q never runs", name, spec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.status.Name == name {
+			return fmt.Errorf("task %s is already registered", name)
+		}
+	}
+	s.entries = append(s.entries, &entry{
+		schedule: schedule,
+		task:     task,
+		status:   TaskStatus{Name: name, Schedule: spec},
+	})
+	return nil
+}
+
+// Start runs the scheduler on its own job pool, with a worker per task so
+// a long task never delays another. It stops with routinewrapper.Shutdown.
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	if len(s.entries) == 0 || s.pool != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	for _, e := range s.entries {
+		s.plan(e, now)
+	}
+	s.pool = routinewrapper.NewPool(PoolName, len(s.entries)+1, len(s.entries))
+	s.mu.Unlock()
+
+	_, err := s.pool.Submit("schedule", s.loop)
+	return err
+}
+
+// Status returns the state of every task, ordered by name
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.entries))
+	for _, e := range s.entries {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// loop waits for the next due task until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		var wake time.Time
+		for _, e := range s.entries {
+			if wake.IsZero() || (!e.fireAt.IsZero() && e.fireAt.Before(wake)) {
+				wake = e.fireAt
+			}
+		}
+		s.mu.Unlock()
+		if wake.IsZero() {
+			<-ctx.Done()
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for _, e := range s.entries {
+			if !e.fireAt.IsZero() && !e.fireAt.After(now) {
+				s.fire(e)
+				s.plan(e, now)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// plan works out the next run of e after its current one, catching up to
+// now when runs were missed. The caller must hold s.mu.
+func (s *Scheduler) plan(e *entry, now time.Time) {
+	from := e.next
+	if from.Before(now) {
+		from = now
+	}
+	e.next = e.schedule.Next(from)
+	e.fireAt = e.next
+	if !e.next.IsZero() && s.jitter > 0 {
+		e.fireAt = e.next.Add(time.Duration(rand.Int63n(int64(s.jitter))))
+	}
+	e.status.NextRun = e.fireAt
+}
+
+// fire submits a run of e unless one is still going. The caller must hold s.mu.
+func (s *Scheduler) fire(e *entry) {
+	name := e.status.Name
+	if e.status.Running {
+		e.status.Skipped++
+		s.logger.Warn("scheduled task still running, skipping this run", zap.String("task", name))
+		return
+	}
+
+	e.status.Running = true
+	_, err := s.pool.Submit(name, func(ctx context.Context) error {
+		return s.run(ctx, e)
+	})
+	if err != nil {
+		e.status.Running = false
+		e.status.Failures++
+		e.status.LastError = err.Error()
+		s.logger.Error("error submitting scheduled task", zap.String("task", name), zap.Error(err))
+	}
+}
+
+// run runs the task of e and records the outcome
+func (s *Scheduler) run(ctx context.Context, e *entry) (err error) {
+	start := time.Now().UTC()
+	s.mu.Lock()
+	e.status.LastStarted = &start
+	name := e.status.Name
+	s.mu.Unlock()
+
+	s.logger.Info("scheduled task started", zap.String("task", name))
+	defer func() {
+		// Panics are recorded here and reported by the pool
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		finish := time.Now().UTC()
+
+		s.mu.Lock()
+		e.status.Running = false
+		e.status.Runs++
+		e.status.LastFinished = &finish
+		e.status.LastDuration = finish.Sub(start)
+		e.status.LastError = ""
+		if err != nil {
+			e.status.Failures++
+			e.status.LastError = err.Error()
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			s.logger.Error("scheduled task failed", zap.String("task", name), zap.Duration("duration", finish.Sub(start)), zap.Error(err))
+		} else {
+			s.logger.Info("scheduled task finished", zap.String("task", name), zap.Duration("duration", finish.Sub(start)))
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	return e.task(ctx)
+}
@@ -0,0 +1,23 @@
+package sentry
+
+import (
+	sentrygo "github.com/getsentry/sentry-go"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+)
+
+// Init configures the global Sentry hub. Without a DSN no client is bound,
+// so capturing and recovering through the hub are no-ops.
+func Init(cfg config.AppConfig) error {
+	if cfg.SentryDSN == "" {
+		return nil
+	}
+
+	return sentrygo.Init(sentrygo.ClientOptions{
+		Dsn:              cfg.SentryDSN,
+		Environment:      cfg.SentryEnvironment,
+		Release:          cfg.SentryRelease,
+		SampleRate:       cfg.SentrySampleRate,
+		AttachStacktrace: true,
+	})
+}
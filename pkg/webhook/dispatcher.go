@@ -0,0 +1,339 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/events"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/routinewrapper"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// PoolName is the job pool deliveries run on
+const PoolName = "webhooks"
+
+// Options tunes the dispatcher
+type Options struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+	// Deliveries kept in the log of each subscription
+	LogSize int
+}
+
+// Delivery is one attempt to deliver an event
+type Delivery struct {
+	ID         string        `json:"id"`
+	EventType  string        `json:"event_type"`
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Time       time.Time     `json:"time"`
+}
+
+// job is a delivery waiting for the pool
+type job struct {
+	id        string
+	sub       Subscription
+	eventType string
+	payload   []byte
+	attempt   int
+}
+
+// retry is a failed delivery waiting for its next attempt
+type retry struct {
+	job       job
+	lastError string
+	timer     *time.Timer
+}
+
+// Dispatcher delivers events to matching subscriptions on a job pool,
+// retrying failures with exponential backoff
+type Dispatcher struct {
+	store  *Store
+	opts   Options
+	client *http.Client
+	logger *zap.Logger
+	pool   *routinewrapper.Pool
+
+	mu      sync.Mutex
+	logs    map[string][]Delivery
+	retries map[string]*retry
+}
+
+// NewDispatcher returns a dispatcher for the subscriptions in store
+func NewDispatcher(store *Store, logger *zap.Logger, opts Options) *Dispatcher {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	d := &Dispatcher{
+		store:   store,
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout},
+		logger:  logger,
+		pool:    routinewrapper.NewPool(PoolName, opts.Workers, opts.QueueSize),
+		logs:    map[string][]Delivery{},
+		retries: map[string]*retry{},
+	}
+	d.pool.OnShutdown(d.deadLetterRetries)
+	return d
+}
+
+// Dispatch queues event for every subscription it matches
+func (d *Dispatcher) Dispatch(event events.Event) {
+	var payload []byte
+	for _, sub := range d.store.Subscriptions() {
+		if !sub.Matches(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				d.logger.Error("error encoding webhook payload", zap.Error(err))
+				return
+			}
+		}
+		d.enqueue(job{id: uuid.NewString(), sub: sub, eventType: event.Type, payload: payload, attempt: 1})
+	}
+}
+
+// Redeliver queues a dead letter again, starting over with the first attempt.
+// The letter was not queued when it returns an error.
+func (d *Dispatcher) Redeliver(letter DeadLetter) error {
+	sub, err := d.store.Subscription(letter.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	return d.submit(job{id: letter.ID, sub: sub, eventType: letter.EventType, payload: letter.Payload, attempt: 1})
+}
+
+// Deliveries returns the latest delivery attempts of a subscription, newest first
+func (d *Dispatcher) Deliveries(subscriptionID string) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	logs := d.logs[subscriptionID]
+	deliveries := make([]Delivery, 0, len(logs))
+	for i := len(logs) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, logs[i])
+	}
+	return deliveries
+}
+
+// enqueue submits j to the pool, a full or shut down pool dead-letters it
+func (d *Dispatcher) enqueue(j job) {
+	if err := d.submit(j); err != nil {
+		d.deadLetter(j, err.Error())
+	}
+}
+
+// submit queues j on the pool
+func (d *Dispatcher) submit(j job) error {
+	_, err := d.pool.Submit("deliver "+j.eventType+" to "+j.sub.ID, func(ctx context.Context) error {
+		return d.deliver(ctx, j)
+	})
+	return err
+}
+
+// deliver makes one attempt and schedules the next one on failure
+func (d *Dispatcher) deliver(ctx context.Context, j job) error {
+	start := time.Now()
+	status, err := d.post(ctx, j)
+	delivery := Delivery{
+		ID:         j.id,
+		EventType:  j.eventType,
+		Attempt:    j.attempt,
+		StatusCode: status,
+		Duration:   time.Since(start),
+		Time:       start.UTC(),
+	}
+	if err == nil && (status < 200 || status > 299) {
+		err = fmt.Errorf("receiver responded with status %d", status)
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	d.record(j.sub.ID, delivery)
+
+	if err == nil {
+		return nil
+	}
+	if j.attempt >= d.opts.MaxAttempts || ctx.Err() != nil {
+		d.deadLetter(j, err.Error())
+		return err
+	}
+
+	backoff := d.backoff(j.attempt)
+	d.logger.Warn("webhook delivery failed, retrying",
+		zap.String("subscription", j.sub.ID),
+		zap.String("delivery", j.id),
+		zap.Int("attempt", j.attempt),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+	d.scheduleRetry(j, backoff, err.Error())
+	return err
+}
+
+// scheduleRetry queues the next attempt of j after backoff. Retries still
+// waiting when the pool shuts down are dead-lettered by deadLetterRetries.
+func (d *Dispatcher) scheduleRetry(j job, backoff time.Duration, lastError string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.retries[j.id] = &retry{job: j, lastError: lastError, timer: time.AfterFunc(backoff, func() {
+		d.mu.Lock()
+		delete(d.retries, j.id)
+		d.mu.Unlock()
+
+		j.attempt++
+		d.enqueue(j)
+	})}
+}
+
+// deadLetterRetries stores the retries still waiting as dead letters, they
+// would be lost with the process otherwise. A retry whose timer already
+// fired finds the pool shut down and is dead-lettered by enqueue.
+func (d *Dispatcher) deadLetterRetries() {
+	d.mu.Lock()
+	var stopped []*retry
+	for id, r := range d.retries {
+		if r.timer.Stop() {
+			stopped = append(stopped, r)
+			delete(d.retries, id)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, r := range stopped {
+		d.deadLetter(r.job, r.lastError)
+	}
+}
+
+// post sends the signed payload and returns the response status
+func (d *Dispatcher) post(ctx context.Context, j job) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.sub.URL, bytes.NewReader(j.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, j.eventType)
+	req.Header.Set(HeaderDelivery, j.id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(j.sub.Secret, timestamp, j.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the attempt after attempt, doubling
+// from Backoff up to MaxBackoff with up to 20% jitter
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.opts.Backoff << (attempt - 1)
+	if delay <= 0 || (d.opts.MaxBackoff > 0 && delay > d.opts.MaxBackoff) {
+		delay = d.opts.MaxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// record appends to the delivery log of a subscription
+func (d *Dispatcher) record(subscriptionID string, delivery Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	logs := append(d.logs[subscriptionID], delivery)
+	if d.opts.LogSize > 0 && len(logs) > d.opts.LogSize {
+		logs = logs[len(logs)-d.opts.LogSize:]
+	}
+	d.logs[subscriptionID] = logs
+}
+
+// deadLetter stores a delivery that will not be attempted again
+func (d *Dispatcher) deadLetter(j job, reason string) {
+	d.logger.Error("webhook delivery dead-lettered",
+		zap.String("subscription", j.sub.ID),
+		zap.String("delivery", j.id),
+		zap.Int("attempts", j.attempt),
+		zap.String("reason", reason),
+	)
+	err := d.store.AddDeadLetter(DeadLetter{
+		ID:             j.id,
+		SubscriptionID: j.sub.ID,
+		EventType:      j.eventType,
+		Payload:        j.payload,
+		Attempts:       j.attempt,
+		LastError:      reason,
+		FailedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		d.logger.Error("error storing webhook dead letter", zap.Error(err))
+	}
+}
+
+// Sign returns the signature header value for a payload: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<payload>" keyed with the subscription secret
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches reports whether the subscription wants event. Event patterns
+// are exact types, "*" or a resource wildcard such as "review.*".
+func (s Subscription) Matches(event events.Event) bool {
+	if len(s.Apps) > 0 && !contains(s.Apps, event.App) {
+		return false
+	}
+	for _, pattern := range s.Events {
+		if pattern == "*" || pattern == event.Type ||
+			(strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event.Type, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
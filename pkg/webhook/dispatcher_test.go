@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/events"
+)
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"type":"app.created"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(payload)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("secret", 1700000000, payload); got != want {
+		t.Errorf("Sign = %s; want %s", got, want)
+	}
+	if Sign("other", 1700000000, payload) == want || Sign("secret", 1700000001, payload) == want {
+		t.Error("the signature must depend on the secret and the timestamp")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		sub    Subscription
+		event  events.Event
+		wanted bool
+	}{
+		{"exact", Subscription{Events: []string{"app.created"}}, events.Event{Type: "app.created"}, true},
+		{"other type", Subscription{Events: []string{"app.created"}}, events.Event{Type: "app.deleted"}, false},
+		{"everything", Subscription{Events: []string{"*"}}, events.Event{Type: "review.created"}, true},
+		{"resource wildcard", Subscription{Events: []string{"review.*"}}, events.Event{Type: "review.created"}, true},
+		{"other resource", Subscription{Events: []string{"review.*"}}, events.Event{Type: "app.created"}, false},
+		{"prefix is not a resource", Subscription{Events: []string{"app.*"}}, events.Event{Type: "apps.created"}, false},
+		{"app filter", Subscription{Events: []string{"*"}, Apps: []string{"Maps"}}, events.Event{Type: "app.created", App: "Maps"}, true},
+		{"other app", Subscription{Events: []string{"*"}, Apps: []string{"Maps"}}, events.Event{Type: "app.created", App: "Mail"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.sub.Matches(tt.event); got != tt.wanted {
+			t.Errorf("%s: Matches = %v; want %v", tt.name, got, tt.wanted)
+		}
+	}
+}
+
+// receiver is an httptest webhook receiver failing the first failures requests
+func receiver(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if r.Header.Get(HeaderSignature) != Sign("secret", timestamp, body) {
+			t.Errorf("delivery %s has a bad signature", r.Header.Get(HeaderDelivery))
+		}
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// newTestDispatcher returns a dispatcher with one subscription to every event
+func newTestDispatcher(t *testing.T, url string, opts Options) (*Dispatcher, *Store) {
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddSubscription(Subscription{ID: "sub", URL: url, Events: []string{"*"}, Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher(store, zap.NewNop(), opts)
+	t.Cleanup(func() { _ = d.pool.Shutdown(context.Background()) })
+	return d, store
+}
+
+// waitFor polls cond for up to a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestDispatchRetriesUntilDelivered(t *testing.T) {
+	server, calls := receiver(t, 2)
+	d, store := newTestDispatcher(t, server.URL, Options{QueueSize: 8, MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second})
+
+	d.Dispatch(events.Event{Type: "app.created", App: "Maps"})
+	waitFor(t, "the third attempt", func() bool { return len(d.Deliveries("sub")) == 3 })
+
+	deliveries := d.Deliveries("sub")
+	if deliveries[0].Attempt != 3 || deliveries[0].StatusCode != http.StatusNoContent || deliveries[0].Error != "" {
+		t.Errorf("last delivery = %+v; want attempt 3 delivered", deliveries[0])
+	}
+	if deliveries[2].StatusCode != http.StatusServiceUnavailable || deliveries[2].Error == "" {
+		t.Errorf("first delivery = %+v; want a failed attempt", deliveries[2])
+	}
+	if calls.Load() != 3 || len(store.DeadLetters()) != 0 {
+		t.Errorf("%d calls, %d dead letters; want 3 calls and none", calls.Load(), len(store.DeadLetters()))
+	}
+}
+
+func TestDispatchDeadLettersAfterMaxAttempts(t *testing.T) {
+	server, _ := receiver(t, 100)
+	d, store := newTestDispatcher(t, server.URL, Options{QueueSize: 8, MaxAttempts: 2, Backoff: time.Millisecond, Timeout: time.Second})
+
+	d.Dispatch(events.Event{Type: "app.created"})
+	waitFor(t, "a dead letter", func() bool { return len(store.DeadLetters()) == 1 })
+
+	letter := store.DeadLetters()[0]
+	if letter.Attempts != 2 || letter.SubscriptionID != "sub" || letter.EventType != "app.created" {
+		t.Errorf("dead letter = %+v; want 2 attempts of app.created for sub", letter)
+	}
+}
+
+func TestShutdownDeadLettersPendingRetries(t *testing.T) {
+	server, _ := receiver(t, 100)
+	d, store := newTestDispatcher(t, server.URL, Options{QueueSize: 8, MaxAttempts: 5, Backoff: time.Hour, Timeout: time.Second})
+
+	d.Dispatch(events.Event{Type: "review.created"})
+	waitFor(t, "the first attempt", func() bool { return len(d.Deliveries("sub")) == 1 })
+	waitFor(t, "the retry to be scheduled", func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.retries) == 1
+	})
+
+	if err := d.pool.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	letters := store.DeadLetters()
+	if len(letters) != 1 || letters[0].Attempts != 1 || letters[0].LastError == "" {
+		t.Fatalf("dead letters = %+v; want the pending retry after 1 attempt", letters)
+	}
+}
+
+func TestRedeliverFailsOnShutDownPool(t *testing.T) {
+	server, _ := receiver(t, 0)
+	d, store := newTestDispatcher(t, server.URL, Options{QueueSize: 8, MaxAttempts: 1, Timeout: time.Second})
+	if err := d.pool.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	letter := DeadLetter{ID: "letter", SubscriptionID: "sub", EventType: "app.created", Payload: []byte(`{}`)}
+	if err := d.Redeliver(letter); err == nil {
+		t.Fatal("Redeliver on a shut down pool succeeded")
+	}
+	if len(store.DeadLetters()) != 0 {
+		t.Error("a refused redelivery must be left to the caller, not dead-lettered again")
+	}
+	if err := d.Redeliver(DeadLetter{SubscriptionID: "gone"}); err != ErrNotFound {
+		t.Errorf("Redeliver for a deleted subscription = %v; want ErrNotFound", err)
+	}
+}
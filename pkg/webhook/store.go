@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// ErrNotFound is returned for unknown subscription or dead letter IDs
+var ErrNotFound = errors.New("webhook not found")
+
+// Subscription is a receiver of change events
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Apps      []string  `json:"apps,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// Redacted returns the subscription without its secret
+func (s Subscription) Redacted() Subscription {
+	s.Secret = ""
+	return s
+}
+
+// DeadLetter is a delivery that failed every attempt
+type DeadLetter struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	FailedAt       time.Time       `json:"failed_at"`
+}
+
+// storeData is the layout of the store file
+type storeData struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	DeadLetters   []DeadLetter   `json:"dead_letters"`
+}
+
+// Store persists subscriptions and dead letters to a JSON file
+type Store struct {
+	path string
+	mu   sync.RWMutex
+	data storeData
+}
+
+// NewStore loads the store persisted at path. An empty path keeps
+// everything in memory only.
+func NewStore(path string) (*Store, error) {
+	store := &Store{path: path}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.data); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Subscriptions returns every subscription
+func (s *Store) Subscriptions() []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Subscription{}, s.data.Subscriptions...)
+}
+
+// Subscription returns the subscription with the given ID
+func (s *Store) Subscription(id string) (Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sub := range s.data.Subscriptions {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return Subscription{}, ErrNotFound
+}
+
+// AddSubscription stores a new subscription
+func (s *Store) AddSubscription(sub Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data
+	data.Subscriptions = append(append([]Subscription{}, data.Subscriptions...), sub)
+	return s.save(data)
+}
+
+// DeleteSubscription removes a subscription
+func (s *Store) DeleteSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data
+	data.Subscriptions = nil
+	found := false
+	for _, sub := range s.data.Subscriptions {
+		if sub.ID == id {
+			found = true
+			continue
+		}
+		data.Subscriptions = append(data.Subscriptions, sub)
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return s.save(data)
+}
+
+// DeadLetters returns every dead letter, oldest first
+func (s *Store) DeadLetters() []DeadLetter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]DeadLetter{}, s.data.DeadLetters...)
+}
+
+// AddDeadLetter stores a failed delivery
+func (s *Store) AddDeadLetter(letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data
+	data.DeadLetters = append(append([]DeadLetter{}, data.DeadLetters...), letter)
+	return s.save(data)
+}
+
+// TakeDeadLetter removes and returns a dead letter so it can be redelivered
+func (s *Store) TakeDeadLetter(id string) (DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data
+	data.DeadLetters = nil
+	var taken *DeadLetter
+	for i, letter := range s.data.DeadLetters {
+		if letter.ID == id {
+			taken = &s.data.DeadLetters[i]
+			continue
+		}
+		data.DeadLetters = append(data.DeadLetters, letter)
+	}
+	if taken == nil {
+		return DeadLetter{}, ErrNotFound
+	}
+	letter := *taken
+	return letter, s.save(data)
+}
+
+// save atomically rewrites the store file and replaces the stored data,
+// the caller must hold s.mu
+func (s *Store) save(data storeData) error {
+	if s.path != "" {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if err := utils.WriteFileAtomic(s.path, raw, 0o600); err != nil {
+			return err
+		}
+	}
+	s.data = data
+	return nil
+}
@@ -10,7 +10,13 @@ import (
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
 	controller "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/controllers/api/v1"
 	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/middlewares"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/audit"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/events"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/idempotency"
 	pMetrics "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/prometheus"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/scheduler"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/webhook"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,36 +28,150 @@ func Setup(app *fiber.App, logger *zap.Logger, config config.AppConfig, pMetrics
 	mu.Lock()
 	defer mu.Unlock()
 
-	app.Use(middlewares.LogHandler(logger, pMetrics))
-
-	router := app.Group("/api")
+	app.Use(middlewares.RequestID(logger))
+	app.Use(middlewares.Actor(logger))
+	app.Use(middlewares.Tracing(logger))
+	app.Use(middlewares.LogHandler(logger, pMetrics, config))
+	app.Use(middlewares.Recover(logger))
+	app.Use(middlewares.CORS(config))
+
+	idempotencyStore, err := idempotency.NewStore(config.IdempotencyStorePath, config.IdempotencyTTL)
+	if err != nil {
+		return err
+	}
+	idempotent := middlewares.Idempotency(logger, idempotencyStore)
+
+	auditLog, err := SetupAudit(logger, config)
+	if err != nil {
+		return err
+	}
+	registerReloadHooks(logger, auditLog)
+
+	webhookStore, err := webhook.NewStore(config.WebhookStorePath)
+	if err != nil {
+		return err
+	}
+	dispatcher := webhook.NewDispatcher(webhookStore, logger, webhook.Options{
+		Workers:     config.WebhookWorkers,
+		QueueSize:   config.WebhookQueueSize,
+		MaxAttempts: config.WebhookMaxAttempts,
+		Backoff:     config.WebhookBackoff,
+		MaxBackoff:  config.WebhookMaxBackoff,
+		Timeout:     config.WebhookTimeout,
+		LogSize:     config.WebhookLogSize,
+	})
+
+	broker := events.NewBroker(config.EventsBufferSize)
+	models.OnMutation(publishEvent(broker, dispatcher))
+	models.OnMutation(models.NewStatsModel(logger, config).Invalidate)
+
+	sched, err := newScheduler(logger, config)
+	if err != nil {
+		return err
+	}
+	if err := sched.Start(); err != nil {
+		return err
+	}
+
+	SetupHealthRoutes(app, logger, config)
+
+	router := app.Group("/api", middlewares.RateLimit(logger, config))
 	v1 := router.Group("/v1")
 
 	// API Endpoints
-	SetupAppRoutes(v1, logger, config)
-	SetupReviewRoutes(v1, logger, config)
+	SetupAppRoutes(v1, logger, config, idempotent)
+	SetupReviewRoutes(v1, logger, config, idempotent)
+	SetupAdminRoutes(v1, logger, config, auditLog, sched)
+	SetupEventRoutes(v1, logger, config, broker)
+	SetupWebhookRoutes(v1, logger, config, webhookStore, dispatcher)
 
 	return nil
 }
 
+// SetupAudit opens the audit log and records every mutation in it and in
+// the revision history. Commands changing data without the server use it too.
+func SetupAudit(logger *zap.Logger, config config.AppConfig) (*audit.Log, error) {
+	auditLog, err := audit.New(config.AuditLogPath)
+	if err != nil {
+		return nil, err
+	}
+	models.OnMutation(recordAudit(logger, auditLog))
+	models.OnMutation(models.NewRevisionModel(logger, config).Record)
+	return auditLog, nil
+}
+
 // SetupAppRoutes defines the routes for app management
-func SetupAppRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig) {
+func SetupAppRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig, idempotent fiber.Handler) {
 	appController := controller.NewAppController(logger, config)
 
 	appGroup := v1.Group("/apps")
-	appGroup.Get("/", appController.ListApps) // Fetch apps with limit, page, and price filter
-	appGroup.Post("/", appController.AddApp)  // Add a new app
+	appGroup.Get("/", appController.ListApps)            // Fetch apps with limit, page, and price filter
+	appGroup.Post("/", idempotent, appController.AddApp) // Add a new app
 	appGroup.Delete(fmt.Sprintf("/:%s", constants.ParamAppName), appController.DeleteApp)
+	appGroup.Post(fmt.Sprintf("/:%s/restore", constants.ParamAppName), appController.RestoreApp) // Undo the last delete
+	appGroup.Get(fmt.Sprintf("/:%s/history", constants.ParamAppName), appController.AppHistory)  // Revisions with diffs
 
 }
 
 // SetupreviewRoutes defines the routes for app management
-func SetupReviewRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig) {
+func SetupReviewRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig, idempotent fiber.Handler) {
 
 	reviewController := controller.NewReviewController(logger, config)
 
 	reviewGroup := v1.Group("/review")
-	reviewGroup.Get("/", reviewController.ListReviews) // Fetch reviews with filters
-	reviewGroup.Post("/", reviewController.AddReview)  //add review with given data
+	reviewGroup.Get("/", reviewController.ListReviews)            // Fetch reviews with filters
+	reviewGroup.Post("/", idempotent, reviewController.AddReview) //add review with given data
 	reviewGroup.Delete(fmt.Sprintf("/:%s", constants.ParamAppName), reviewController.DeleteReview)
+	reviewGroup.Post(fmt.Sprintf("/:%s/restore", constants.ParamAppName), reviewController.RestoreReviews) // Undo the last delete
+}
+
+// SetupAdminRoutes defines the routes for dataset maintenance, only
+// administrators may call them
+func SetupAdminRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig, auditLog *audit.Log, sched *scheduler.Scheduler) {
+	adminController := controller.NewAdminController(logger, config, auditLog, sched)
+
+	adminGroup := v1.Group("/admin", middlewares.AdminOnly(logger, config))
+	adminGroup.Get("/duplicates", adminController.ListDuplicates)         // Duplicate apps and reviews report
+	adminGroup.Post("/duplicates/merge", adminController.MergeDuplicates) // Keep the most recently updated row
+	adminGroup.Get("/trash", adminController.ListTrash)                   // Deleted apps and reviews
+	adminGroup.Post("/trash/purge", adminController.PurgeTrash)           // Drop entries older than the retention
+	adminGroup.Get("/quarantine", adminController.ListQuarantine)         // Rows skipped when loading the CSVs
+	adminGroup.Get("/audit", adminController.ListAudit)                   // Who changed what and when
+	adminGroup.Get("/jobs", adminController.ListJobs)                     // Background job pools
+	adminGroup.Get("/schedule", adminController.ListSchedule)             // Scheduled tasks and their last run
+	adminGroup.Get("/stats", adminController.Stats)                       // Dataset aggregates
+	adminGroup.Get("/log-level", adminController.GetLogLevel)             // Current root log level
+	adminGroup.Put("/log-level", adminController.SetLogLevel)             // Change it without a restart
+	adminGroup.Post("/config/reload", adminController.ReloadConfig)       // Same as SIGHUP
+}
+
+// SetupHealthRoutes defines the liveness and readiness probes
+func SetupHealthRoutes(app *fiber.App, logger *zap.Logger, config config.AppConfig) {
+	healthController := controller.NewHealthController(logger, config)
+
+	healthGroup := app.Group("/health")
+	healthGroup.Get("/live", healthController.Live)   // Process is up
+	healthGroup.Get("/ready", healthController.Ready) // Accepting traffic
+}
+
+// SetupEventRoutes defines the change feed
+func SetupEventRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig, broker *events.Broker) {
+	eventController := controller.NewEventController(logger, config, broker)
+
+	v1.Get("/events", eventController.Stream) // Server-Sent Events for every change
+}
+
+// SetupWebhookRoutes defines the routes for webhook subscriptions, only
+// administrators may call them
+func SetupWebhookRoutes(v1 fiber.Router, logger *zap.Logger, config config.AppConfig, store *webhook.Store, dispatcher *webhook.Dispatcher) {
+	webhookController := controller.NewWebhookController(logger, config, store, dispatcher)
+
+	webhookGroup := v1.Group("/webhooks", middlewares.AdminOnly(logger, config))
+	webhookGroup.Get("/", webhookController.ListWebhooks)
+	webhookGroup.Post("/", webhookController.CreateWebhook)
+	webhookGroup.Get("/dead-letters", webhookController.ListDeadLetters)                                            // Deliveries that failed every attempt
+	webhookGroup.Post(fmt.Sprintf("/dead-letters/:%s/retry", constants.ParamID), webhookController.RetryDeadLetter) // Deliver again
+	webhookGroup.Get(fmt.Sprintf("/:%s", constants.ParamID), webhookController.GetWebhook)
+	webhookGroup.Delete(fmt.Sprintf("/:%s", constants.ParamID), webhookController.DeleteWebhook)
+	webhookGroup.Get(fmt.Sprintf("/:%s/deliveries", constants.ParamID), webhookController.ListDeliveries) // Latest delivery attempts
 }
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/audit"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/events"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/webhook"
+)
+
+// recordAudit writes every committed mutation to the audit log. The change
+// is already stored, so a failed write is logged rather than returned.
+func recordAudit(logger *zap.Logger, auditLog *audit.Log) models.MutationObserver {
+	return func(ctx context.Context, mutation models.Mutation) {
+		if mutation.Action == models.ActionReload {
+			return
+		}
+		err := auditLog.Append(audit.Entry{
+			Time:      mutation.Time,
+			Actor:     mutation.Actor,
+			RequestID: mutation.RequestID,
+			Action:    mutation.Action,
+			Entity:    mutation.Entity,
+			Key:       mutation.Key,
+			Before:    mutation.Before,
+			After:     mutation.After,
+		})
+		if err != nil {
+			log.FromContext(ctx, logger).Error(constants.ErrWritingAuditLog, zap.Error(err))
+		}
+	}
+}
+
+// eventTypes maps mutation actions to the verb of the published event
+var eventTypes = map[string]string{
+	models.ActionCreate:  "created",
+	models.ActionRestore: "created",
+	models.ActionUpdate:  "updated",
+	models.ActionMerge:   "updated",
+	models.ActionDelete:  "deleted",
+	models.ActionImport:  "reloaded",
+	models.ActionReload:  "reloaded",
+}
+
+// publishEvent publishes app, review and dataset changes to the change
+// feed and to the webhook subscriptions
+func publishEvent(broker *events.Broker, dispatcher *webhook.Dispatcher) models.MutationObserver {
+	return func(ctx context.Context, mutation models.Mutation) {
+		verb, ok := eventTypes[mutation.Action]
+		if !ok || mutation.Entity == models.EntityTrash {
+			return
+		}
+
+		event := events.Event{
+			Type:     mutation.Entity + "." + verb,
+			Resource: mutation.Entity,
+			Time:     mutation.Time,
+			Data:     mutation,
+		}
+		if mutation.Entity != models.EntityDataset {
+			event.App = mutation.Key
+		}
+		dispatcher.Dispatch(broker.Publish(event))
+	}
+}
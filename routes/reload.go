@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	log "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/audit"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// registerReloadHooks applies and audits the settings a config reload
+// changes. Middlewares follow reloads by themselves.
+func registerReloadHooks(logger *zap.Logger, auditLog *audit.Log) {
+	config.OnReload(applyLogLevel(logger))
+	config.OnReload(recordReload(logger, auditLog))
+}
+
+// applyLogLevel sets the root log level when a reload changes LOG_LEVEL
+// or DEBUG. A level set through the admin API stays until then.
+func applyLogLevel(logger *zap.Logger) config.ReloadHook {
+	return func(ctx context.Context, cfg config.AppConfig, changes []config.Change) {
+		if !config.Changed(changes, "LOG_LEVEL", "DEBUG") {
+			return
+		}
+		level, err := log.ConfiguredLevel(cfg.LogLevel, cfg.Debug)
+		if err != nil {
+			log.FromContext(ctx, logger).Error(constants.ErrInvalidLogLevel, zap.Error(err))
+			return
+		}
+		log.Level().SetLevel(level)
+	}
+}
+
+// recordReload writes the settings a reload changed to the audit log
+func recordReload(logger *zap.Logger, auditLog *audit.Log) config.ReloadHook {
+	return func(ctx context.Context, _ config.AppConfig, changes []config.Change) {
+		before := map[string]interface{}{}
+		after := map[string]interface{}{}
+		for _, change := range changes {
+			before[change.Key] = change.Before
+			after[change.Key] = change.After
+		}
+
+		err := auditLog.Append(audit.Entry{
+			Time:      time.Now().UTC(),
+			Actor:     utils.ActorFromContext(ctx),
+			RequestID: utils.RequestIDFromContext(ctx),
+			Action:    models.ActionReload,
+			Entity:    constants.AuditEntityConfig,
+			Before:    before,
+			After:     after,
+		})
+		if err != nil {
+			log.FromContext(ctx, logger).Error(constants.ErrWritingAuditLog, zap.Error(err))
+		}
+	}
+}
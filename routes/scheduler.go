@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/config"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/models"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/scheduler"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/utils"
+)
+
+// newScheduler registers the maintenance tasks that have a schedule
+// configured. Their changes are recorded as made by the scheduler actor.
+func newScheduler(logger *zap.Logger, config config.AppConfig) (*scheduler.Scheduler, error) {
+	sched := scheduler.New(logger, config.ScheduleJitter)
+
+	tasks := []struct {
+		name string
+		spec string
+		task scheduler.Task
+	}{
+		{"compact", config.ScheduleCompaction, func(ctx context.Context) error {
+			for _, compact := range []func(context.Context) (models.CompactResult, error){
+				models.NewAppModel(logger, config).Compact,
+				models.NewReviewModel(logger, config).Compact,
+			} {
+				result, err := compact(ctx)
+				if err != nil {
+					return err
+				}
+				logger.Info("compacted CSV file",
+					zap.String("path", result.Path),
+					zap.Int("rows", result.Rows),
+					zap.Int64("bytes_before", result.BytesBefore),
+					zap.Int64("bytes_after", result.BytesAfter),
+				)
+			}
+			return nil
+		}},
+		{"snapshot", config.ScheduleSnapshot, func(ctx context.Context) error {
+			dir, err := models.NewSnapshotModel(logger, config).Take(ctx)
+			if err == nil {
+				logger.Info("took snapshot", zap.String("dir", dir))
+			}
+			return err
+		}},
+		{"purge-trash", config.ScheduleTrashPurge, func(ctx context.Context) error {
+			purged, err := models.NewTrashModel(logger, config).Purge(ctx, config.TrashRetention)
+			if err == nil {
+				logger.Info("purged trash", zap.Int("purged", purged), zap.Duration("older_than", config.TrashRetention))
+			}
+			return err
+		}},
+		{"refresh-stats", config.ScheduleStatsRefresh, func(ctx context.Context) error {
+			_, err := models.NewStatsModel(logger, config).Refresh(ctx)
+			return err
+		}},
+	}
+
+	for _, t := range tasks {
+		if t.spec == "" {
+			continue
+		}
+		task := t.task
+		err := sched.Register(t.name, t.spec, func(ctx context.Context) error {
+			return task(utils.ContextWithActor(ctx, constants.SchedulerActor))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sched, nil
+}
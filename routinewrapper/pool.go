@@ -0,0 +1,298 @@
+package routinewrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pMetrics "git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/prometheus"
+)
+
+// Errors returned by Submit
+var (
+	ErrQueueFull  = errors.New("job queue is full")
+	ErrPoolClosed = errors.New("job pool is shut down")
+)
+
+// Job states
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StatePanicked  = "panicked"
+	StateCancelled = "cancelled"
+)
+
+// historySize is how many finished jobs a pool remembers for Status
+const historySize = 100
+
+// Job is a named unit of background work. The context is cancelled when
+// the pool shuts down.
+type Job func(ctx context.Context) error
+
+// JobStatus describes one submitted job
+type JobStatus struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// PoolStatus is a snapshot of a pool
+type PoolStatus struct {
+	Name    string      `json:"name"`
+	Workers int         `json:"workers"`
+	Queued  int         `json:"queued"`
+	Running int         `json:"running"`
+	Closed  bool        `json:"closed"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+// task is a submitted job waiting for a worker
+type task struct {
+	id   uint64
+	name string
+	job  Job
+}
+
+// Pool runs submitted jobs on a bounded number of workers
+type Pool struct {
+	name    string
+	workers int
+	queue   chan task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	metrics *pMetrics.PrometheusMetrics
+
+	mu       sync.Mutex
+	closed   bool
+	hooks    []func()
+	nextID   uint64
+	active   map[uint64]*JobStatus
+	finished []JobStatus
+}
+
+// Registered pools, for Pools and Shutdown
+var (
+	pools     []*Pool
+	poolMutex sync.Mutex
+)
+
+// NewPool starts a pool of workers with room for queueSize waiting jobs
+// and registers it for Pools and Shutdown
+func NewPool(name string, workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		name:    name,
+		workers: workers,
+		queue:   make(chan task, queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		metrics: pMetrics.InitPrometheusMetrics(),
+		active:  map[uint64]*JobStatus{},
+	}
+
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for t := range p.queue {
+				p.run(t)
+			}
+		}()
+	}
+
+	poolMutex.Lock()
+	pools = append(pools, p)
+	poolMutex.Unlock()
+	return p
+}
+
+// Submit queues a job, it fails when the queue is full or the pool is shut down
+func (p *Pool) Submit(name string, job Job) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrPoolClosed
+	}
+
+	p.nextID++
+	t := task{id: p.nextID, name: name, job: job}
+	select {
+	case p.queue <- t:
+	default:
+		return 0, ErrQueueFull
+	}
+
+	p.active[t.id] = &JobStatus{ID: t.id, Name: name, State: StateQueued, QueuedAt: time.Now().UTC()}
+	p.metrics.JobsQueued.WithLabelValues(p.name).Inc()
+	return t.id, nil
+}
+
+// run runs one job, recording its outcome
+func (p *Pool) run(t task) {
+	p.transition(t.id, StateRunning, "")
+	p.metrics.JobsQueued.WithLabelValues(p.name).Dec()
+	p.metrics.JobsRunning.WithLabelValues(p.name).Inc()
+	defer p.metrics.JobsRunning.WithLabelValues(p.name).Dec()
+
+	state, message := StateSucceeded, ""
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				state, message = StatePanicked, fmt.Sprint(r)
+				reportPanic(r)
+			}
+		}()
+		if err := t.job(p.ctx); err != nil {
+			state, message = StateFailed, err.Error()
+			if p.ctx.Err() != nil {
+				state = StateCancelled
+			}
+		}
+	}()
+
+	if state == StateSucceeded {
+		p.metrics.JobsCompleted.WithLabelValues(p.name).Inc()
+	} else {
+		p.metrics.JobsFailed.WithLabelValues(p.name, state).Inc()
+	}
+	p.transition(t.id, state, message)
+}
+
+// transition moves a job to state, finished jobs go to the history
+func (p *Pool) transition(id uint64, state, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status, ok := p.active[id]
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	status.State = state
+	status.Error = message
+	if state == StateRunning {
+		status.StartedAt = &now
+		return
+	}
+
+	status.FinishedAt = &now
+	delete(p.active, id)
+	p.finished = append(p.finished, *status)
+	if len(p.finished) > historySize {
+		p.finished = p.finished[len(p.finished)-historySize:]
+	}
+}
+
+// Status returns the queued and running jobs and the latest finished ones
+func (p *Pool) Status() PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := PoolStatus{Name: p.name, Workers: p.workers, Closed: p.closed}
+	for _, job := range p.active {
+		if job.State == StateRunning {
+			status.Running++
+		} else {
+			status.Queued++
+		}
+		status.Jobs = append(status.Jobs, *job)
+	}
+	status.Jobs = append(status.Jobs, p.finished...)
+	sort.Slice(status.Jobs, func(i, j int) bool { return status.Jobs[i].ID > status.Jobs[j].ID })
+	return status
+}
+
+// OnShutdown registers fn to run once Shutdown has stopped the workers or
+// gave up waiting for them, to save work that was waiting outside the pool
+func (p *Pool) OnShutdown(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hooks = append(p.hooks, fn)
+}
+
+// Shutdown stops accepting jobs, cancels the context of running jobs and
+// waits for the workers until ctx is done. Jobs still queued run with the
+// cancelled context so they can give up or save their work. The OnShutdown
+// hooks run last.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	hooks := p.hooks
+	p.mu.Unlock()
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("pool %s: %w", p.name, ctx.Err())
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return err
+}
+
+// Pools returns the status of every pool
+func Pools() []PoolStatus {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+
+	statuses := make([]PoolStatus, 0, len(pools))
+	for _, p := range pools {
+		statuses = append(statuses, p.Status())
+	}
+	return statuses
+}
+
+// Shutdown shuts every pool down, see Pool.Shutdown
+func Shutdown(ctx context.Context) error {
+	poolMutex.Lock()
+	registered := append([]*Pool{}, pools...)
+	poolMutex.Unlock()
+
+	var errs []error
+	for _, p := range registered {
+		if err := p.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportPanic hands a recovered panic to the handler registered with Init.
+// The handler calls recover itself, so the panic is raised again inside a
+// function that defers it.
+func reportPanic(r interface{}) {
+	if handle == nil {
+		return
+	}
+	func() {
+		defer handle()
+		panic(r)
+	}()
+}
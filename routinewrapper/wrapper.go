@@ -15,6 +15,9 @@ func Init(fn func()) {
 }
 
 func RoutineGenerator(fn func()) {
-	defer handle()
+	// handle is unset until Init, e.g. when packages are used on their own
+	if handle != nil {
+		defer handle()
+	}
 	fn()
 }
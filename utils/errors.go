@@ -0,0 +1,32 @@
+package utils
+
+// APIError is an error carrying the HTTP status and the stable
+// machine-readable code it should be reported with
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error
+}
+
+// NewAPIError creates an APIError
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// WrapAPIError creates an APIError caused by err. Sentinel errors wrapped
+// in err still take precedence when the response is built.
+func WrapAPIError(err error, status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message, Err: err}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"clevergo.tech/jsend"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/pkg/tracing"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -12,17 +14,35 @@ type JSONResponse struct {
 
 // JSONSuccess is a generic success output writer
 func JSONSuccess(c *fiber.Ctx, statusCode int, data interface{}) error {
-	return c.Status(statusCode).JSON(jsend.New(data))
+	_, span := tracing.Start(c.UserContext(), "json.encode")
+	err := c.Status(statusCode).JSON(jsend.New(data))
+	tracing.End(span, err)
+	return err
+}
+
+// requestBody is a jsend body carrying the ID of the request it answers,
+// so clients can quote it whatever the status
+type requestBody struct {
+	jsend.Body
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // JSONFail is a generic fail output writer
 // JSONFail can used for 4xx status code response
+// The request ID is added next to the data so clients can quote it
 func JSONFail(c *fiber.Ctx, statusCode int, data interface{}) error {
-	return c.Status(statusCode).JSON(jsend.NewFail(data))
+	return c.Status(statusCode).JSON(requestBody{Body: jsend.NewFail(data), RequestID: RequestID(c)})
 }
 
 // JSONError is a generic error output writer
 // JSONError can used for 5xx status code response
+// The request ID is added next to the data, and echoed in it for clients
+// reading it from there
 func JSONError(c *fiber.Ctx, statusCode int, err string) error {
-	return c.Status(statusCode).JSON(jsend.NewError(err, statusCode, nil))
+	var data interface{}
+	requestID := RequestID(c)
+	if requestID != "" {
+		data = map[string]string{constants.LogFieldRequestID: requestID}
+	}
+	return c.Status(statusCode).JSON(requestBody{Body: jsend.NewError(err, statusCode, data), RequestID: requestID})
 }
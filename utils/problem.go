@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Problem is an RFC 7807 problem details object
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// AcceptsProblem reports whether the client asked for problem+json
+func AcceptsProblem(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), constants.MIMEProblemJSON)
+}
+
+// JSONProblem writes an application/problem+json response.
+// typeBaseURL is prefixed to the code to build the problem type URI,
+// without it the type is "about:blank" as allowed by the RFC.
+func JSONProblem(c *fiber.Ctx, statusCode int, code, detail, typeBaseURL string, errors interface{}) error {
+	problemType := "about:blank"
+	if typeBaseURL != "" {
+		problemType = strings.TrimSuffix(typeBaseURL, "/") + "/" + code
+	}
+
+	return c.Status(statusCode).JSON(Problem{
+		Type:      problemType,
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    detail,
+		Instance:  c.OriginalURL(),
+		Code:      code,
+		RequestID: RequestID(c),
+		Errors:    errors,
+	}, constants.MIMEProblemJSON)
+}
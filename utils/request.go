@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"crypto/x509"
+
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/constants"
+	"git.pride.improwised.dev/Onboarding-2025/Yash-Tilala/fiber-csv-app/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or "" if none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestID returns the ID assigned to the current request by the RequestID middleware
+func RequestID(c *fiber.Ctx) string {
+	requestID, _ := c.Locals(constants.LocalRequestID).(string)
+	return requestID
+}
+
+// RequestLogger returns the request-scoped logger, or fallback outside of a request
+func RequestLogger(c *fiber.Ctx, fallback *zap.Logger) *zap.Logger {
+	return logger.FromContext(c.UserContext(), fallback)
+}
+
+type actorKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying the identity making the request
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or the anonymous actor
+func ActorFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return constants.AnonymousActor
+}
+
+// ClientCertificate returns the verified certificate the client presented
+// over mutual TLS, or nil
+func ClientCertificate(c *fiber.Ctx) *x509.Certificate {
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// ClientIdentity names the mutual TLS client: the certificate common name,
+// else its first DNS name or email address. It is empty without a verified
+// client certificate.
+func ClientIdentity(c *fiber.Ctx) string {
+	cert := ClientCertificate(c)
+	switch {
+	case cert == nil:
+		return ""
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	}
+	return ""
+}
+
+// Actor returns the identity assigned to the current request by the Actor middleware
+func Actor(c *fiber.Ctx) string {
+	return ActorFromContext(c.UserContext())
+}
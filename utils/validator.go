@@ -1,15 +1,141 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/go-playground/validator/v10"
 )
 
 const VALIDATE_MESSAGE = "fields are invalid."
 
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+
+	// "0", "4.99" or "$4.99"
+	pricePattern = regexp.MustCompile(`^\$?\d+(\.\d{1,2})?$`)
+	// Play Store install buckets such as "10,000+", "10000+" or "0"
+	installsPattern = regexp.MustCompile(`^(\d{1,3}(,\d{3})*|\d+)\+?$`)
+	// "4.0.3 and up", "4.4W and up", "4.1 - 7.1.1", "8.0" or "Varies with device".
+	// A W suffix marks Android Wear versions.
+	androidVersionPattern = regexp.MustCompile(`^(Varies with device|\d+(\.\d+){0,2}W?( and up| - \d+(\.\d+){0,2}W?)?)$`)
+)
+
+// FieldError describes a single failed validation rule
+type FieldError struct {
+	Field    string `json:"field"`
+	JSONName string `json:"json_name"`
+	Rule     string `json:"rule"`
+	Param    string `json:"param,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ValidationError is returned by ValidateStruct and lists every failed field
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		names = append(names, strings.ToLower(field.Field))
+	}
+	return fmt.Sprintf("%s %s", strings.Join(names, ","), VALIDATE_MESSAGE)
+}
+
+// Validator returns the shared validator instance.
+// validator.Validate caches struct metadata, so it is built only once.
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+
+		// Report fields by the name clients send them with
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		_ = validate.RegisterValidation("price", matchPattern(pricePattern))
+		_ = validate.RegisterValidation("installs", matchPattern(installsPattern))
+		_ = validate.RegisterValidation("android_version", matchPattern(androidVersionPattern))
+	})
+	return validate
+}
+
+// matchPattern builds a string validator from a regular expression
+func matchPattern(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(strings.TrimSpace(fl.Field().String()))
+	}
+}
+
+// ValidateStruct validates s with the shared validator.
+// Rule failures are returned as a *ValidationError.
+func ValidateStruct(s interface{}) error {
+	err := Validator().Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	return &ValidationError{Fields: ValidationDetails(validationErrors)}
+}
+
+// ValidationDetails converts validator errors into FieldErrors
+func ValidationDetails(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:    fe.StructField(),
+			JSONName: fe.Field(),
+			Rule:     fe.Tag(),
+			Param:    fe.Param(),
+			Message:  fieldMessage(fe),
+		})
+	}
+	return fields
+}
+
+// fieldMessage returns a human readable message for a failed rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", fe.Field(), fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", fe.Field(), fe.Param())
+	case "http_url":
+		return fmt.Sprintf("%s must be an http or https URL", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must have at least %s entries", fe.Field(), fe.Param())
+	case "price":
+		return fmt.Sprintf("%s must be a price such as 0, 4.99 or $4.99", fe.Field())
+	case "installs":
+		return fmt.Sprintf("%s must be an install bucket such as 10,000+", fe.Field())
+	case "android_version":
+		return fmt.Sprintf("%s must be an Android version such as 4.0.3 and up or Varies with device", fe.Field())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+	}
+}
+
 func ValidateEmail(email string) (bool, error) {
 	return regexp.MatchString("[a-zA-z]+@improwised.com", email)
 }
@@ -17,7 +143,11 @@ func ValidateEmail(email string) (bool, error) {
 func ValidatorErrorString(err error) string {
 	var msg string
 	if err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err.Error()
+		}
+		for _, err := range validationErrors {
 			msg += strings.ToLower(err.Field()) + ","
 		}
 		msg = strings.TrimSuffix(msg, ",")
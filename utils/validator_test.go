@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestAndroidVersionPattern(t *testing.T) {
+	valid := []string{
+		"4.0.3 and up",
+		"4.4W and up",
+		"4.1 - 7.1.1",
+		"4.4W - 7.1.1",
+		"8.0",
+		"Varies with device",
+	}
+	for _, value := range valid {
+		if !androidVersionPattern.MatchString(value) {
+			t.Errorf("%q should be a valid Android version", value)
+		}
+	}
+
+	invalid := []string{"", "and up", "4.0.3.1 and up", "4.4X and up", "NaN"}
+	for _, value := range invalid {
+		if androidVersionPattern.MatchString(value) {
+			t.Errorf("%q should not be a valid Android version", value)
+		}
+	}
+}
+
+func TestPricePattern(t *testing.T) {
+	for _, value := range []string{"0", "4.99", "$4.99", "$0"} {
+		if !pricePattern.MatchString(value) {
+			t.Errorf("%q should be a valid price", value)
+		}
+	}
+	for _, value := range []string{"", "Free", "4.999", "$"} {
+		if pricePattern.MatchString(value) {
+			t.Errorf("%q should not be a valid price", value)
+		}
+	}
+}
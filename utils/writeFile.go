@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file at path with data. The data is written
+// to a temporary file in the same directory, synced and renamed over path,
+// so a crash leaves either the old or the new file, never a truncated one.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicFunc(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// WriteFileAtomicFunc replaces the file at path with what write writes, the
+// way WriteFileAtomic does, without holding the whole content in memory.
+// The file is left untouched when write fails.
+func WriteFileAtomicFunc(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	buffered := bufio.NewWriter(tmp)
+	if err := write(buffered); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return SyncDir(dir)
+}
+
+// SyncFile flushes the file at path to disk. A missing file is not an error.
+func SyncFile(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// SyncDir flushes a directory so a rename inside it survives a crash
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// FileChanged stats path and reports whether it was replaced or modified
+// since last, the info an earlier call returned. A missing file has nil
+// info, so it counts as changed once it appears or disappears.
+func FileChanged(path string, last os.FileInfo) (os.FileInfo, bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, last != nil, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if last == nil {
+		return info, true, nil
+	}
+	changed := !os.SameFile(info, last) || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size()
+	return info, changed, nil
+}